@@ -0,0 +1,270 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+const (
+	defaultQueueSize = 1024
+	defaultDropLevel = LevelWarn
+)
+
+// OverflowPolicy determines what an asynchronous logger does when its queue is full.
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	// OverflowBlock blocks the logging call until there is space in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the new log entry.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest log entry in the queue to make space for the new one.
+	OverflowDropOldest
+	// OverflowDropBelow drops the new log entry if it is less severe than the drop level and otherwise blocks.
+	OverflowDropBelow
+)
+
+// AsyncOptions are configurations for logging asynchronously.
+//
+// Log entries are queued in a bounded queue of QueueSize entries (1024 by default) and written to the outputs by a background goroutine.
+// Overflow determines what happens when the queue is full.
+// When Overflow is OverflowDropBelow, entries less severe than DropLevel are dropped (e.g. LevelWarn drops info and debug entries).
+// DropLevel is LevelWarn by default, since LevelNone would drop all entries.
+// DrainTimeout is the maximum duration for writing the queued entries when the logger is closed.
+// The entries not written before the timeout are dropped, but the entry being written is not interrupted.
+// A zero DrainTimeout means waiting until all queued entries are written.
+type AsyncOptions struct {
+	QueueSize    int
+	Overflow     OverflowPolicy
+	DropLevel    Level
+	DrainTimeout time.Duration
+}
+
+// validate checks the options for logging asynchronously.
+func (o AsyncOptions) validate() error {
+	var err error
+
+	if o.QueueSize < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid async queue size %d: cannot be negative", o.QueueSize))
+	}
+
+	if o.Overflow < OverflowBlock || o.Overflow > OverflowDropBelow {
+		err = multierr.Append(err, fmt.Errorf("invalid async overflow policy %d", o.Overflow))
+	}
+
+	if o.DropLevel < LevelNone || o.DropLevel > LevelTrace {
+		err = multierr.Append(err, fmt.Errorf("invalid async drop level %d", o.DropLevel))
+	}
+
+	if o.DrainTimeout < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid async drain timeout %s: cannot be negative", o.DrainTimeout))
+	}
+
+	return err
+}
+
+// AsyncStats are the counters of an asynchronous logger.
+type AsyncStats struct {
+	Queued  int
+	Dropped uint64
+}
+
+// asyncStatser is implemented by loggers that can log asynchronously.
+type asyncStatser interface {
+	asyncStats() AsyncStats
+}
+
+// GetAsyncStats returns the counters of an asynchronous logger.
+// For a logger that does not log asynchronously, it returns zero values.
+func GetAsyncStats(l Logger) AsyncStats {
+	if as, ok := l.(asyncStatser); ok {
+		return as.asyncStats()
+	}
+	return AsyncStats{}
+}
+
+// asyncEntry is a queued log entry.
+type asyncEntry struct {
+	level Level
+	write func()
+}
+
+// asyncQueue is a bounded ring buffer of log entries written by a background goroutine.
+type asyncQueue struct {
+	opts    AsyncOptions
+	dropped uint64
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	entries []asyncEntry
+	head    int
+	count   int
+	busy    bool
+	closed  bool
+	done    chan struct{}
+}
+
+func newAsyncQueue(opts AsyncOptions) *asyncQueue {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+
+	if opts.Overflow == OverflowDropBelow && opts.DropLevel == LevelNone {
+		opts.DropLevel = defaultDropLevel
+	}
+
+	q := &asyncQueue{
+		opts:    opts,
+		entries: make([]asyncEntry, opts.QueueSize),
+		done:    make(chan struct{}),
+	}
+
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+
+	return q
+}
+
+// run writes the queued entries until the queue is closed and drained.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+		for q.count == 0 && !q.closed {
+			q.cond.Wait()
+		}
+
+		if q.count == 0 {
+			q.mu.Unlock()
+			return
+		}
+
+		e := q.entries[q.head]
+		q.entries[q.head] = asyncEntry{}
+		q.head = (q.head + 1) % len(q.entries)
+		q.count--
+		q.busy = true
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		e.write()
+
+		q.mu.Lock()
+		q.busy = false
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// push adds a new log entry to the queue according to the overflow policy.
+func (q *asyncQueue) push(level Level, write func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && q.count == len(q.entries) {
+		switch q.opts.Overflow {
+		case OverflowDropNewest:
+			atomic.AddUint64(&q.dropped, 1)
+			return
+
+		case OverflowDropOldest:
+			q.entries[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.entries)
+			q.count--
+			atomic.AddUint64(&q.dropped, 1)
+
+		case OverflowDropBelow:
+			if level > q.opts.DropLevel {
+				atomic.AddUint64(&q.dropped, 1)
+				return
+			}
+			q.cond.Wait()
+
+		default:
+			q.cond.Wait()
+		}
+	}
+
+	// Log entries are dropped once the queue is closed.
+	if q.closed {
+		atomic.AddUint64(&q.dropped, 1)
+		return
+	}
+
+	tail := (q.head + q.count) % len(q.entries)
+	q.entries[tail] = asyncEntry{
+		level: level,
+		write: write,
+	}
+	q.count++
+	q.cond.Broadcast()
+}
+
+// sync waits until all queued entries are written.
+func (q *asyncQueue) sync() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.count > 0 || q.busy {
+		q.cond.Wait()
+	}
+}
+
+// close stops accepting new entries and waits for the queued entries to be written.
+// If a drain timeout is set, the entries not written before the timeout are dropped.
+// It returns only after the background goroutine has stopped, so the outputs can be closed safely.
+func (q *asyncQueue) close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	if q.opts.DrainTimeout <= 0 {
+		<-q.done
+		return nil
+	}
+
+	timer := time.NewTimer(q.opts.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-timer.C:
+		q.mu.Lock()
+		n := q.count
+		for ; q.count > 0; q.count-- {
+			q.entries[q.head] = asyncEntry{}
+			q.head = (q.head + 1) % len(q.entries)
+		}
+		atomic.AddUint64(&q.dropped, uint64(n))
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		// The entry being written is not interrupted.
+		<-q.done
+
+		return fmt.Errorf("async queue not drained after %s: %d entries dropped", q.opts.DrainTimeout, n)
+	}
+}
+
+// stats returns the current counters of the queue.
+func (q *asyncQueue) stats() AsyncStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return AsyncStats{
+		Queued:  q.count,
+		Dropped: atomic.LoadUint64(&q.dropped),
+	}
+}
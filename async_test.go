@@ -0,0 +1,397 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the writes of queued entries.
+type recorder struct {
+	sync.Mutex
+	written []string
+}
+
+func (r *recorder) write(name string) func() {
+	return func() {
+		r.Lock()
+		defer r.Unlock()
+		r.written = append(r.written, name)
+	}
+}
+
+func (r *recorder) Written() []string {
+	r.Lock()
+	defer r.Unlock()
+	return r.written
+}
+
+// newBlockedQueue creates a queue whose background goroutine is blocked until the returned channel is closed.
+// The queue is then filled up with entries named by their indices.
+func newBlockedQueue(t *testing.T, opts AsyncOptions, r *recorder) (*asyncQueue, chan struct{}) {
+	q := newAsyncQueue(opts)
+	unblock := make(chan struct{})
+	started := make(chan struct{})
+
+	q.push(LevelError, func() {
+		close(started)
+		<-unblock
+	})
+
+	<-started
+
+	for i := 0; i < opts.QueueSize; i++ {
+		q.push(LevelInfo, r.write(string(rune('a'+i))))
+	}
+
+	assert.Equal(t, opts.QueueSize, q.stats().Queued)
+
+	return q, unblock
+}
+
+func TestGetAsyncStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		logger        Logger
+		expectedStats AsyncStats
+	}{
+		{
+			name:          "NopLogger",
+			logger:        NewNopLogger(),
+			expectedStats: AsyncStats{},
+		},
+		{
+			name:          "SyncKit",
+			logger:        NewKit(Options{}),
+			expectedStats: AsyncStats{},
+		},
+		{
+			name:          "SyncZap",
+			logger:        NewZap(Options{}),
+			expectedStats: AsyncStats{},
+		},
+		{
+			name:          "AsyncKit",
+			logger:        NewKit(Options{Async: &AsyncOptions{}}),
+			expectedStats: AsyncStats{},
+		},
+		{
+			name:          "AsyncZap",
+			logger:        NewZap(Options{Async: &AsyncOptions{}}),
+			expectedStats: AsyncStats{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stats := GetAsyncStats(tc.logger)
+
+			assert.Equal(t, tc.expectedStats, stats)
+		})
+	}
+}
+
+func TestAsyncOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          AsyncOptions
+		expectedError string
+	}{
+		{
+			name: "Default",
+			opts: AsyncOptions{},
+		},
+		{
+			name: "Valid",
+			opts: AsyncOptions{QueueSize: 10, Overflow: OverflowDropBelow, DropLevel: LevelInfo, DrainTimeout: time.Second},
+		},
+		{
+			name:          "Invalid",
+			opts:          AsyncOptions{QueueSize: -1, Overflow: OverflowPolicy(9), DropLevel: Level(-1), DrainTimeout: -time.Second},
+			expectedError: "invalid async queue size -1: cannot be negative; invalid async overflow policy 9; invalid async drop level -1; invalid async drain timeout -1s: cannot be negative",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.validate()
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewAsyncQueue(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         AsyncOptions
+		expectedSize int
+	}{
+		{
+			name:         "Default",
+			opts:         AsyncOptions{},
+			expectedSize: defaultQueueSize,
+		},
+		{
+			name:         "QueueSize",
+			opts:         AsyncOptions{QueueSize: 10},
+			expectedSize: 10,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newAsyncQueue(tc.opts)
+
+			assert.Len(t, q.entries, tc.expectedSize)
+			assert.NoError(t, q.close())
+		})
+	}
+}
+
+func TestAsyncQueue_Block(t *testing.T) {
+	r := new(recorder)
+	q, unblock := newBlockedQueue(t, AsyncOptions{QueueSize: 2, Overflow: OverflowBlock}, r)
+
+	pushed := make(chan struct{})
+	go func() {
+		q.push(LevelDebug, r.write("c"))
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push should block when the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(unblock)
+	<-pushed
+	assert.NoError(t, q.close())
+
+	assert.Equal(t, []string{"a", "b", "c"}, r.Written())
+	assert.Equal(t, uint64(0), q.stats().Dropped)
+}
+
+func TestAsyncQueue_DropNewest(t *testing.T) {
+	r := new(recorder)
+	q, unblock := newBlockedQueue(t, AsyncOptions{QueueSize: 2, Overflow: OverflowDropNewest}, r)
+
+	q.push(LevelError, r.write("c"))
+
+	close(unblock)
+	assert.NoError(t, q.close())
+
+	assert.Equal(t, []string{"a", "b"}, r.Written())
+	assert.Equal(t, uint64(1), q.stats().Dropped)
+}
+
+func TestAsyncQueue_DropOldest(t *testing.T) {
+	r := new(recorder)
+	q, unblock := newBlockedQueue(t, AsyncOptions{QueueSize: 2, Overflow: OverflowDropOldest}, r)
+
+	q.push(LevelInfo, r.write("c"))
+	q.push(LevelInfo, r.write("d"))
+
+	close(unblock)
+	assert.NoError(t, q.close())
+
+	assert.Equal(t, []string{"c", "d"}, r.Written())
+	assert.Equal(t, uint64(2), q.stats().Dropped)
+}
+
+func TestAsyncQueue_DropBelow(t *testing.T) {
+	r := new(recorder)
+	q, unblock := newBlockedQueue(t, AsyncOptions{QueueSize: 2, Overflow: OverflowDropBelow, DropLevel: LevelWarn}, r)
+
+	q.push(LevelDebug, r.write("debug"))
+	q.push(LevelInfo, r.write("info"))
+
+	pushed := make(chan struct{})
+	go func() {
+		q.push(LevelWarn, r.write("warn"))
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push should block for entries not less severe than the drop level")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(unblock)
+	<-pushed
+	assert.NoError(t, q.close())
+
+	assert.Equal(t, []string{"a", "b", "warn"}, r.Written())
+	assert.Equal(t, uint64(2), q.stats().Dropped)
+}
+
+func TestAsyncQueue_DropBelowDefault(t *testing.T) {
+	r := new(recorder)
+	q, unblock := newBlockedQueue(t, AsyncOptions{QueueSize: 2, Overflow: OverflowDropBelow}, r)
+	assert.Equal(t, LevelWarn, q.opts.DropLevel)
+
+	q.push(LevelInfo, r.write("info"))
+
+	pushed := make(chan struct{})
+	go func() {
+		q.push(LevelError, r.write("error"))
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("push should block for entries not less severe than the default drop level")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(unblock)
+	<-pushed
+	assert.NoError(t, q.close())
+
+	assert.Equal(t, []string{"a", "b", "error"}, r.Written())
+	assert.Equal(t, uint64(1), q.stats().Dropped)
+}
+
+func TestAsyncQueue_Sync(t *testing.T) {
+	r := new(recorder)
+	q := newAsyncQueue(AsyncOptions{})
+
+	for _, name := range []string{"a", "b", "c"} {
+		q.push(LevelInfo, r.write(name))
+	}
+
+	q.sync()
+	assert.Equal(t, []string{"a", "b", "c"}, r.Written())
+	assert.Equal(t, AsyncStats{}, q.stats())
+
+	assert.NoError(t, q.close())
+}
+
+func TestAsyncQueue_Close(t *testing.T) {
+	t.Run("Drained", func(t *testing.T) {
+		r := new(recorder)
+		q := newAsyncQueue(AsyncOptions{DrainTimeout: time.Second})
+
+		q.push(LevelInfo, r.write("a"))
+		assert.NoError(t, q.close())
+		assert.NoError(t, q.close())
+
+		q.push(LevelInfo, r.write("b"))
+
+		assert.Equal(t, []string{"a"}, r.Written())
+		assert.Equal(t, uint64(1), q.stats().Dropped)
+	})
+
+	t.Run("DrainTimeout", func(t *testing.T) {
+		r := new(recorder)
+		q, unblock := newBlockedQueue(t, AsyncOptions{QueueSize: 2, DrainTimeout: 50 * time.Millisecond}, r)
+
+		closed := make(chan error)
+		go func() {
+			closed <- q.close()
+		}()
+
+		// The queued entries are dropped after the timeout, but the entry being written is waited for.
+		assert.Eventually(t, func() bool {
+			return q.stats().Dropped == 2
+		}, time.Second, time.Millisecond)
+
+		select {
+		case <-closed:
+			t.Fatal("close should wait for the entry being written")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(unblock)
+		assert.EqualError(t, <-closed, "async queue not drained after 50ms: 2 entries dropped")
+
+		select {
+		case <-q.done:
+		default:
+			t.Fatal("the background goroutine should be stopped")
+		}
+
+		assert.Empty(t, r.Written())
+		assert.Equal(t, AsyncStats{Queued: 0, Dropped: 2}, q.stats())
+	})
+}
+
+func TestAsync_Loggers(t *testing.T) {
+	for _, tc := range testBackends {
+		t.Run(tc.name, func(t *testing.T) {
+			main, high := new(bytes.Buffer), new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Writers: []io.Writer{main},
+				Routes: []Route{
+					{From: LevelWarn, To: LevelError, Writers: []io.Writer{high}},
+				},
+				Async: &AsyncOptions{
+					QueueSize:    4,
+					DrainTimeout: time.Second,
+				},
+			})
+
+			for i := 0; i < 10; i++ {
+				logger.With("index", i).Info("info message")
+			}
+			logger.Error("error message")
+
+			assert.NoError(t, logger.Close())
+
+			assert.Equal(t, 10, strings.Count(main.String(), "info message"))
+			assert.Equal(t, 1, strings.Count(high.String(), "error message"))
+			assert.Equal(t, AsyncStats{}, GetAsyncStats(logger))
+		})
+
+		t.Run(tc.name+"DrainTimeout", func(t *testing.T) {
+			buf := &lockedBuffer{}
+			slow := writerFunc(func(p []byte) (int, error) {
+				time.Sleep(20 * time.Millisecond)
+				return buf.Write(p)
+			})
+
+			logger := tc.newLogger(Options{
+				Writers: []io.Writer{slow},
+				Async: &AsyncOptions{
+					QueueSize:    10,
+					DrainTimeout: 30 * time.Millisecond,
+				},
+			})
+
+			for i := 0; i < 10; i++ {
+				logger.Info("info message")
+			}
+
+			err := logger.Close()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "async queue not drained after 30ms")
+
+			// Nothing is written to the outputs after they are closed.
+			written := buf.String()
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, written, buf.String())
+
+			stats := GetAsyncStats(logger)
+			assert.Equal(t, 0, stats.Queued)
+			assert.Equal(t, uint64(10), uint64(strings.Count(written, "info message"))+stats.Dropped)
+		})
+	}
+}
+
+// writerFunc is an io.Writer implemented by a function.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
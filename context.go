@@ -0,0 +1,190 @@
+package log
+
+import "context"
+
+type contextKey struct{}
+
+// contextValue is stored in a context for carrying a logger and key-value pairs.
+// logger and caller already include the key-value pairs.
+// caller is the same logger adjusted for being called by the package-level context functions.
+// If no logger is set, kv is used with the singleton logger.
+type contextValue struct {
+	logger Logger
+	caller Logger
+	kv     []interface{}
+}
+
+func contextValueFrom(ctx context.Context) *contextValue {
+	if ctx != nil {
+		if v, ok := ctx.Value(contextKey{}).(*contextValue); ok {
+			return v
+		}
+	}
+	return new(contextValue)
+}
+
+// NewContext returns a new context that carries a logger.
+// The key-value pairs already added to the context using WithContextFields are added to the logger.
+// If the logger is nil, the new context does not carry a logger and the singleton logger is used instead.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	v := contextValueFrom(ctx)
+
+	nv := &contextValue{
+		kv: v.kv,
+	}
+
+	if l != nil {
+		nv.logger = l
+		if len(v.kv) > 0 {
+			nv.logger = l.With(v.kv...)
+		}
+		nv.caller = addCallerSkip(nv.logger, 1)
+	}
+
+	return context.WithValue(ctx, contextKey{}, nv)
+}
+
+// WithContextFields returns a new context that carries a list of key-value pairs in addition to the ones already in the context.
+// The key-value pairs are logged by the logger returned from FromContext.
+func WithContextFields(ctx context.Context, kv ...interface{}) context.Context {
+	v := contextValueFrom(ctx)
+
+	nv := &contextValue{
+		kv: append(v.kv[:len(v.kv):len(v.kv)], kv...),
+	}
+
+	if v.logger != nil {
+		nv.logger = v.logger.With(kv...)
+		nv.caller = v.caller.With(kv...)
+	}
+
+	return context.WithValue(ctx, contextKey{}, nv)
+}
+
+// FromContext returns the logger carried by a context including the key-value pairs added to the context.
+// If the context does not carry a logger, the singleton logger is used.
+// If the singleton logger is not set either, a nop logger is returned.
+// If the context carries a trace context, the trace context is logged too (see SetTraceExtractor).
+// If the context forces a logging level, the logger logs in the forced level too (see ForceLevel).
+func FromContext(ctx context.Context) Logger {
+	v := contextValueFrom(ctx)
+	if v.logger != nil {
+		return withForcedLevel(ctx, withTraceContext(ctx, v.logger))
+	}
+
+	logger := Singleton()
+	if logger == nil {
+		return NewNopLogger()
+	}
+
+	if len(v.kv) > 0 {
+		logger = logger.With(v.kv...)
+	}
+
+	return withForcedLevel(ctx, withTraceContext(ctx, logger))
+}
+
+// callerFromContext returns the logger carried by a context adjusted for being called by the package-level context functions.
+func callerFromContext(ctx context.Context) Logger {
+	v := contextValueFrom(ctx)
+	if v.caller != nil {
+		return withForcedLevel(ctx, withTraceContext(ctx, v.caller))
+	}
+
+	logger := getSingleton()
+	if logger == nil {
+		return nil
+	}
+
+	if len(v.kv) > 0 {
+		logger = logger.With(v.kv...)
+	}
+
+	return withForcedLevel(ctx, withTraceContext(ctx, logger))
+}
+
+// LogContext logs a message and a list of key-value pairs in a given level using the logger carried by a context.
+// It can be used for logging in trace level too (LogContext(ctx, LevelTrace, ...)).
+// If the context does not carry a logger, the singleton logger is used.
+func LogContext(ctx context.Context, level Level, message string, kv ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Log(level, message, kv...)
+	}
+}
+
+// LogfContext formats and logs a message in a given level using the logger carried by a context.
+// It uses fmt.Sprintf() to log a message.
+// If the context does not carry a logger, the singleton logger is used.
+func LogfContext(ctx context.Context, level Level, format string, v ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Logf(level, format, v...)
+	}
+}
+
+// DebugContext logs a message and a list of key-value pairs in debug level using the logger carried by a context.
+// If the context does not carry a logger, the singleton logger is used.
+func DebugContext(ctx context.Context, message string, kv ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Debug(message, kv...)
+	}
+}
+
+// DebugfContext formats and logs a message in debug level using the logger carried by a context.
+// It uses fmt.Sprintf() to log a message.
+// If the context does not carry a logger, the singleton logger is used.
+func DebugfContext(ctx context.Context, format string, v ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Debugf(format, v...)
+	}
+}
+
+// InfoContext logs a message and a list of key-value pairs in info level using the logger carried by a context.
+// If the context does not carry a logger, the singleton logger is used.
+func InfoContext(ctx context.Context, message string, kv ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Info(message, kv...)
+	}
+}
+
+// InfofContext formats and logs a message in info level using the logger carried by a context.
+// It uses fmt.Sprintf() to log a message.
+// If the context does not carry a logger, the singleton logger is used.
+func InfofContext(ctx context.Context, format string, v ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Infof(format, v...)
+	}
+}
+
+// WarnContext logs a message and a list of key-value pairs in warn level using the logger carried by a context.
+// If the context does not carry a logger, the singleton logger is used.
+func WarnContext(ctx context.Context, message string, kv ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Warn(message, kv...)
+	}
+}
+
+// WarnfContext formats and logs a message in warn level using the logger carried by a context.
+// It uses fmt.Sprintf() to log a message.
+// If the context does not carry a logger, the singleton logger is used.
+func WarnfContext(ctx context.Context, format string, v ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Warnf(format, v...)
+	}
+}
+
+// ErrorContext logs a message and a list of key-value pairs in error level using the logger carried by a context.
+// If the context does not carry a logger, the singleton logger is used.
+func ErrorContext(ctx context.Context, message string, kv ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Error(message, kv...)
+	}
+}
+
+// ErrorfContext formats and logs a message in error level using the logger carried by a context.
+// It uses fmt.Sprintf() to log a message.
+// If the context does not carry a logger, the singleton logger is used.
+func ErrorfContext(ctx context.Context, format string, v ...interface{}) {
+	if l := callerFromContext(ctx); l != nil {
+		l.Errorf(format, v...)
+	}
+}
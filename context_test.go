@@ -0,0 +1,248 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContext(t *testing.T) {
+	defer ReplaceSingleton(nil)()
+
+	t.Run("Logger", func(t *testing.T) {
+		logger := &mockLogger{}
+		ctx := NewContext(context.Background(), logger)
+
+		assert.Equal(t, logger, FromContext(ctx))
+	})
+
+	t.Run("LoggerWithFields", func(t *testing.T) {
+		child := &mockLogger{}
+		logger := &mockLogger{WithOutLogger: child}
+
+		ctx := WithContextFields(context.Background(), "requestId", "1234")
+		ctx = NewContext(ctx, logger)
+
+		assert.Equal(t, child, FromContext(ctx))
+		assert.Equal(t, []interface{}{"requestId", "1234"}, logger.WithInKV)
+	})
+
+	t.Run("NilLogger", func(t *testing.T) {
+		singleton := &mockLogger{}
+		SetSingleton(singleton)
+
+		ctx := NewContext(context.Background(), &mockLogger{})
+		ctx = NewContext(ctx, nil)
+
+		assert.Equal(t, singleton, FromContext(ctx))
+	})
+}
+
+func TestWithContextFields(t *testing.T) {
+	child2 := &mockLogger{}
+	child1 := &mockLogger{WithOutLogger: child2}
+	logger := &mockLogger{WithOutLogger: child1}
+
+	parent := NewContext(context.Background(), logger)
+	ctx1 := WithContextFields(parent, "requestId", "1234")
+	ctx2 := WithContextFields(ctx1, "tenantId", "abcd")
+
+	assert.Equal(t, logger, FromContext(parent))
+	assert.Equal(t, child1, FromContext(ctx1))
+	assert.Equal(t, child2, FromContext(ctx2))
+	assert.Equal(t, []interface{}{"requestId", "1234"}, logger.WithInKV)
+	assert.Equal(t, []interface{}{"tenantId", "abcd"}, child1.WithInKV)
+
+	assert.Equal(t, []interface{}{"requestId", "1234"}, contextValueFrom(ctx1).kv)
+	assert.Equal(t, []interface{}{"requestId", "1234", "tenantId", "abcd"}, contextValueFrom(ctx2).kv)
+}
+
+func TestFromContext(t *testing.T) {
+	t.Run("NoSingleton", func(t *testing.T) {
+		defer ReplaceSingleton(nil)()
+
+		logger := FromContext(context.Background())
+
+		assert.Equal(t, NewNopLogger(), logger)
+	})
+
+	t.Run("Singleton", func(t *testing.T) {
+		singleton := &mockLogger{}
+		defer ReplaceSingleton(singleton)()
+
+		logger := FromContext(context.Background())
+
+		assert.Equal(t, singleton, logger)
+	})
+
+	t.Run("SingletonWithFields", func(t *testing.T) {
+		child := &mockLogger{}
+		singleton := &mockLogger{WithOutLogger: child}
+		defer ReplaceSingleton(singleton)()
+
+		ctx := WithContextFields(context.Background(), "requestId", "1234")
+		logger := FromContext(ctx)
+
+		assert.Equal(t, child, logger)
+		assert.Equal(t, []interface{}{"requestId", "1234"}, singleton.WithInKV)
+	})
+}
+
+func TestLogContext(t *testing.T) {
+	message := "operation succeeded"
+	kv := []interface{}{"operation", "test"}
+	format := "operation succeeded: %s"
+	args := []interface{}{"test"}
+
+	t.Run("NoLogger", func(t *testing.T) {
+		defer ReplaceSingleton(nil)()
+
+		ctx := context.Background()
+
+		LogContext(ctx, LevelTrace, message, kv...)
+		LogfContext(ctx, LevelTrace, format, args...)
+		DebugContext(ctx, message, kv...)
+		DebugfContext(ctx, format, args...)
+		InfoContext(ctx, message, kv...)
+		InfofContext(ctx, format, args...)
+		WarnContext(ctx, message, kv...)
+		WarnfContext(ctx, format, args...)
+		ErrorContext(ctx, message, kv...)
+		ErrorfContext(ctx, format, args...)
+	})
+
+	t.Run("Singleton", func(t *testing.T) {
+		singleton := &mockLogger{}
+		defer ReplaceSingleton(singleton)()
+
+		ctx := context.Background()
+
+		LogContext(ctx, LevelTrace, message, kv...)
+		LogfContext(ctx, LevelTrace, format, args...)
+		DebugContext(ctx, message, kv...)
+		DebugfContext(ctx, format, args...)
+		InfoContext(ctx, message, kv...)
+		InfofContext(ctx, format, args...)
+		WarnContext(ctx, message, kv...)
+		WarnfContext(ctx, format, args...)
+		ErrorContext(ctx, message, kv...)
+		ErrorfContext(ctx, format, args...)
+
+		assert.Equal(t, LevelTrace, singleton.LogInLevel)
+		assert.Equal(t, message, singleton.LogInMessage)
+		assert.Equal(t, kv, singleton.LogInKV)
+		assert.Equal(t, LevelTrace, singleton.LogfInLevel)
+		assert.Equal(t, format, singleton.LogfInFormat)
+		assert.Equal(t, args, singleton.LogfInArgs)
+		assert.Equal(t, format, singleton.DebugfInFormat)
+		assert.Equal(t, args, singleton.DebugfInArgs)
+		assert.Equal(t, format, singleton.InfofInFormat)
+		assert.Equal(t, args, singleton.InfofInArgs)
+		assert.Equal(t, format, singleton.WarnfInFormat)
+		assert.Equal(t, args, singleton.WarnfInArgs)
+		assert.Equal(t, format, singleton.ErrorfInFormat)
+		assert.Equal(t, args, singleton.ErrorfInArgs)
+		assert.Equal(t, message, singleton.DebugInMessage)
+		assert.Equal(t, kv, singleton.DebugInKV)
+		assert.Equal(t, message, singleton.InfoInMessage)
+		assert.Equal(t, kv, singleton.InfoInKV)
+		assert.Equal(t, message, singleton.WarnInMessage)
+		assert.Equal(t, kv, singleton.WarnInKV)
+		assert.Equal(t, message, singleton.ErrorInMessage)
+		assert.Equal(t, kv, singleton.ErrorInKV)
+	})
+
+	t.Run("Logger", func(t *testing.T) {
+		defer ReplaceSingleton(&mockLogger{})()
+
+		logger := &mockLogger{}
+		ctx := NewContext(context.Background(), logger)
+
+		LogContext(ctx, LevelTrace, message, kv...)
+		LogfContext(ctx, LevelTrace, format, args...)
+		DebugContext(ctx, message, kv...)
+		DebugfContext(ctx, format, args...)
+		InfoContext(ctx, message, kv...)
+		InfofContext(ctx, format, args...)
+		WarnContext(ctx, message, kv...)
+		WarnfContext(ctx, format, args...)
+		ErrorContext(ctx, message, kv...)
+		ErrorfContext(ctx, format, args...)
+
+		assert.Equal(t, LevelTrace, logger.LogInLevel)
+		assert.Equal(t, message, logger.LogInMessage)
+		assert.Equal(t, kv, logger.LogInKV)
+		assert.Equal(t, LevelTrace, logger.LogfInLevel)
+		assert.Equal(t, format, logger.LogfInFormat)
+		assert.Equal(t, args, logger.LogfInArgs)
+		assert.Equal(t, format, logger.DebugfInFormat)
+		assert.Equal(t, args, logger.DebugfInArgs)
+		assert.Equal(t, format, logger.InfofInFormat)
+		assert.Equal(t, args, logger.InfofInArgs)
+		assert.Equal(t, format, logger.WarnfInFormat)
+		assert.Equal(t, args, logger.WarnfInArgs)
+		assert.Equal(t, format, logger.ErrorfInFormat)
+		assert.Equal(t, args, logger.ErrorfInArgs)
+		assert.Equal(t, message, logger.DebugInMessage)
+		assert.Equal(t, kv, logger.DebugInKV)
+		assert.Equal(t, message, logger.InfoInMessage)
+		assert.Equal(t, kv, logger.InfoInKV)
+		assert.Equal(t, message, logger.WarnInMessage)
+		assert.Equal(t, kv, logger.WarnInKV)
+		assert.Equal(t, message, logger.ErrorInMessage)
+		assert.Equal(t, kv, logger.ErrorInKV)
+	})
+}
+
+func TestContext_Loggers(t *testing.T) {
+	for _, tc := range testBackends {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Writers: []io.Writer{buf},
+			})
+
+			ctx := WithContextFields(context.Background(), "requestId", "1234")
+			ctx = NewContext(ctx, logger)
+			ctx = WithContextFields(ctx, "tenantId", "abcd")
+
+			InfoContext(ctx, "request received")
+			InfofContext(ctx, "request %s", "validated")
+			LogfContext(ctx, LevelWarn, "request %s", "slow")
+			FromContext(ctx).Info("request processed")
+
+			assert.Contains(t, buf.String(), "request validated")
+			assert.Contains(t, buf.String(), "request slow")
+			assert.Equal(t, 4, bytes.Count(buf.Bytes(), []byte(`"requestId":"1234"`)))
+			assert.Equal(t, 4, bytes.Count(buf.Bytes(), []byte(`"tenantId":"abcd"`)))
+			assert.Equal(t, 4, bytes.Count(buf.Bytes(), []byte("context_test.go:")))
+			assert.NotContains(t, buf.String(), "context.go:")
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+
+	for _, tc := range testLoggers {
+		t.Run(tc.name+"Singleton", func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Writers: []io.Writer{buf},
+			})
+
+			defer ReplaceSingleton(logger)()
+
+			ctx := WithContextFields(context.Background(), "requestId", "1234")
+
+			WarnContext(ctx, "request failed")
+
+			assert.Contains(t, buf.String(), `"requestId":"1234"`)
+			assert.Contains(t, buf.String(), "context_test.go:")
+			assert.NotContains(t, buf.String(), "context.go:")
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+}
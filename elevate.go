@@ -0,0 +1,137 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// elevation is a temporary change of a logging level.
+type elevation struct {
+	level Level
+	timer *time.Timer
+}
+
+// elevationStack keeps the active elevations of a logging level shared by loggers.
+// The level is the level of the latest active elevation and it is restored when no elevation is active.
+type elevationStack struct {
+	restore    func()
+	elevations []*elevation
+}
+
+// elevations keeps the elevation stacks by the logging levels they change.
+var elevations = struct {
+	sync.Mutex
+	stacks map[interface{}]*elevationStack
+}{
+	stacks: map[interface{}]*elevationStack{},
+}
+
+// validateLevelFor checks the arguments of SetLevelFor.
+func validateLevelFor(level Level, d time.Duration) error {
+	if err := level.validate(); err != nil {
+		return err
+	}
+
+	if d <= 0 {
+		return fmt.Errorf("invalid duration %s: must be positive", d)
+	}
+
+	return nil
+}
+
+// setLevelFor changes the logging level of a logger for a duration.
+// key identifies the logging level shared by the logger and other loggers.
+func setLevelFor(l Logger, key interface{}, level Level, d time.Duration) (cancel func(), err error) {
+	if err := validateLevelFor(level, d); err != nil {
+		return func() {}, err
+	}
+
+	elevations.Lock()
+
+	s, ok := elevations.stacks[key]
+	if !ok {
+		s = &elevationStack{
+			restore: saveLevel(l),
+		}
+		elevations.stacks[key] = s
+	}
+
+	from := l.GetLevel()
+	e := &elevation{
+		level: level,
+	}
+
+	s.elevations = append(s.elevations, e)
+	_ = l.SetLevelTo(level)
+
+	e.timer = time.AfterFunc(d, func() {
+		endElevation(l, key, e)
+	})
+
+	elevations.Unlock()
+
+	// The change is logged without holding the lock, so a blocking output does not block other changes.
+	logLevelChange(l, from, level, "logging level changed", "duration", d.String())
+
+	return func() {
+		endElevation(l, key, e)
+	}, nil
+}
+
+// endElevation ends an elevation of a logging level if it is still active.
+// The level is changed to the level of the latest remaining elevation or restored to how it was before the first elevation.
+func endElevation(l Logger, key interface{}, e *elevation) {
+	elevations.Lock()
+
+	s, ok := elevations.stacks[key]
+	if !ok {
+		elevations.Unlock()
+		return
+	}
+
+	i := 0
+	for i < len(s.elevations) && s.elevations[i] != e {
+		i++
+	}
+
+	if i == len(s.elevations) {
+		elevations.Unlock()
+		return
+	}
+
+	e.timer.Stop()
+	s.elevations = append(s.elevations[:i], s.elevations[i+1:]...)
+
+	from := l.GetLevel()
+	if n := len(s.elevations); n > 0 {
+		if level := s.elevations[n-1].level; level != from {
+			_ = l.SetLevelTo(level)
+		}
+	} else {
+		delete(elevations.stacks, key)
+		s.restore()
+	}
+	to := l.GetLevel()
+
+	elevations.Unlock()
+
+	// The change is logged without holding the lock, so a blocking output does not block other changes.
+	if from != to {
+		logLevelChange(l, from, to, "logging level reverted")
+	}
+}
+
+// logLevelChange logs a change of the logging level of a logger.
+// The change is logged in the less verbose level of the two levels, so it is logged in both levels.
+// If one of the levels is LevelNone, the change is logged in the other level.
+// The level is forced, so the change is logged even if the logger does not log in the level anymore.
+func logLevelChange(l Logger, from, to Level, message string, kv ...interface{}) {
+	level := to
+	if (from < to && from != LevelNone) || to == LevelNone {
+		level = from
+	}
+
+	kv = append([]interface{}{"from", from.String(), "to", to.String()}, kv...)
+	forceLevel(l, level).Log(level, message, kv...)
+}
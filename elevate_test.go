@@ -0,0 +1,234 @@
+package log
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLevelFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         Level
+		duration      time.Duration
+		expectedError string
+	}{
+		{
+			name:     "OK",
+			level:    LevelDebug,
+			duration: time.Minute,
+		},
+		{
+			name:          "InvalidLevel",
+			level:         Level(6),
+			duration:      time.Minute,
+			expectedError: "invalid level 6: must be between LevelNone and LevelTrace",
+		},
+		{
+			name:          "ZeroDuration",
+			level:         LevelDebug,
+			duration:      0,
+			expectedError: "invalid duration 0s: must be positive",
+		},
+		{
+			name:          "NegativeDuration",
+			level:         LevelDebug,
+			duration:      -time.Minute,
+			expectedError: "invalid duration -1m0s: must be positive",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateLevelFor(tc.level, tc.duration)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// blockingWriter is a writer that blocks until a channel is closed.
+type blockingWriter chan struct{}
+
+func (w blockingWriter) Write(p []byte) (int, error) {
+	<-w
+	return len(p), nil
+}
+
+func TestSetLevelFor_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &lockedBuffer{}
+			logger := tc.newLogger(Options{
+				Level:   "info",
+				Writers: []io.Writer{buf},
+			})
+
+			_, err := logger.SetLevelFor(Level(6), time.Minute)
+			assert.EqualError(t, err, "invalid level 6: must be between LevelNone and LevelTrace")
+			_, err = logger.SetLevelFor(LevelDebug, 0)
+			assert.EqualError(t, err, "invalid duration 0s: must be positive")
+			assert.Equal(t, LevelInfo, logger.GetLevel())
+
+			// The level is changed back after the duration.
+			_, err = logger.SetLevelFor(LevelDebug, 20*time.Millisecond)
+			assert.NoError(t, err)
+			assert.Equal(t, LevelDebug, logger.GetLevel())
+			assert.Eventually(t, func() bool {
+				return logger.GetLevel() == LevelInfo
+			}, time.Second, time.Millisecond)
+
+			assert.Contains(t, buf.String(), "logging level changed")
+			assert.Contains(t, buf.String(), `"duration":"20ms"`)
+			assert.Contains(t, buf.String(), "logging level reverted")
+			assert.Contains(t, buf.String(), `"from":"debug"`)
+			assert.Contains(t, buf.String(), `"to":"info"`)
+
+			// Overlapping changes are changed back to the level before the first change when all of them have ended.
+			cancel1, err := logger.SetLevelFor(LevelDebug, time.Hour)
+			assert.NoError(t, err)
+			cancel2, err := logger.With("key", "value").SetLevelFor(LevelTrace, time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, LevelTrace, logger.GetLevel())
+
+			cancel1()
+			assert.Equal(t, LevelTrace, logger.GetLevel())
+			cancel2()
+			assert.Equal(t, LevelInfo, logger.GetLevel())
+
+			cancel1, err = logger.SetLevelFor(LevelDebug, time.Hour)
+			assert.NoError(t, err)
+			_, err = logger.SetLevelFor(LevelTrace, 20*time.Millisecond)
+			assert.NoError(t, err)
+			assert.Eventually(t, func() bool {
+				return logger.GetLevel() == LevelDebug
+			}, time.Second, time.Millisecond)
+
+			cancel1()
+			cancel1()
+			assert.Equal(t, LevelInfo, logger.GetLevel())
+
+			// Changing to a less verbose level is logged in the less verbose level.
+			cancel1, err = logger.SetLevelFor(LevelError, time.Hour)
+			assert.NoError(t, err)
+			assert.Contains(t, buf.String(), `"to":"error"`)
+			cancel1()
+			assert.Equal(t, LevelInfo, logger.GetLevel())
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+
+	t.Run("Nop", func(t *testing.T) {
+		logger := NewNopLogger()
+
+		cancel, err := logger.SetLevelFor(LevelDebug, time.Minute)
+		assert.NoError(t, err)
+		assert.NotNil(t, cancel)
+		cancel()
+
+		_, err = logger.SetLevelFor(LevelDebug, 0)
+		assert.EqualError(t, err, "invalid duration 0s: must be positive")
+		assert.Equal(t, LevelNone, logger.GetLevel())
+	})
+
+	t.Run("BlockingOutput", func(t *testing.T) {
+		release := make(chan struct{})
+		blocked := NewKit(Options{Level: "info", Writers: []io.Writer{blockingWriter(release)}})
+		logger := NewZap(Options{Level: "info", Writers: []io.Writer{ioutil.Discard}})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = blocked.SetLevelFor(LevelDebug, time.Hour)
+		}()
+
+		// A change of another logger is not blocked by the logger writing to the blocking output.
+		assert.Eventually(t, func() bool {
+			return blocked.GetLevel() == LevelDebug
+		}, time.Second, time.Millisecond)
+
+		cancel, err := logger.SetLevelFor(LevelDebug, time.Hour)
+		assert.NoError(t, err)
+		cancel()
+		assert.Equal(t, LevelInfo, logger.GetLevel())
+
+		close(release)
+		<-done
+	})
+
+	t.Run("Shared", func(t *testing.T) {
+		logger := NewKit(Options{Level: "info", Writers: []io.Writer{ioutil.Discard}})
+		child := logger.With("key", "value")
+		detached := logger.WithLevel(LevelWarn)
+
+		cancel, err := child.SetLevelFor(LevelDebug, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, LevelDebug, logger.GetLevel())
+		assert.Equal(t, LevelWarn, detached.GetLevel())
+
+		cancel()
+		assert.Equal(t, LevelInfo, logger.GetLevel())
+	})
+
+	for _, tc := range testLoggers {
+		t.Run(tc.name+"None", func(t *testing.T) {
+			buf := &lockedBuffer{}
+			logger := tc.newLogger(Options{
+				Level:   "none",
+				Writers: []io.Writer{buf},
+			})
+
+			// The changes from and to LevelNone are logged in the other level.
+			cancel, err := logger.SetLevelFor(LevelDebug, time.Hour)
+			assert.NoError(t, err)
+			cancel()
+			assert.Equal(t, LevelNone, logger.GetLevel())
+
+			assert.Contains(t, buf.String(), "logging level changed")
+			assert.Contains(t, buf.String(), "logging level reverted")
+			assert.Contains(t, buf.String(), `"to":"none"`)
+			assert.NotContains(t, buf.String(), `"level":"none"`)
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+
+	for _, tc := range testLoggers {
+		t.Run(tc.name+"Named", func(t *testing.T) {
+			logger := tc.newLogger(Options{
+				Level:     "info",
+				LevelSpec: "http=warn",
+				Writers:   []io.Writer{ioutil.Discard},
+			})
+
+			// A named logger without its own level goes back to using the level of the root logger.
+			db := logger.Named("db")
+			cancel, err := db.SetLevelFor(LevelDebug, time.Hour)
+			assert.NoError(t, err)
+			assert.Equal(t, LevelDebug, db.GetLevel())
+			cancel()
+			assert.Equal(t, LevelInfo, db.GetLevel())
+			logger.SetLevel("error")
+			assert.Equal(t, LevelError, db.GetLevel())
+
+			// A named logger with its own level goes back to its own level.
+			http := logger.Named("http")
+			cancel, err = http.SetLevelFor(LevelDebug, time.Hour)
+			assert.NoError(t, err)
+			cancel()
+			assert.Equal(t, LevelWarn, http.GetLevel())
+			logger.SetLevel("info")
+			assert.Equal(t, LevelWarn, http.GetLevel())
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package log
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// flightRecorder keeps the last log entries of the loggers with the flight recorder enabled in a ring buffer.
+// An entry is a list of key-value pairs including its timestamp, level, caller, and context.
+type flightRecorder struct {
+	sync.Mutex
+	entries [][]interface{}
+	next    int
+	count   int
+}
+
+// flight is the process-wide flight recorder shared by all loggers.
+var flight = new(flightRecorder)
+
+// optionsFlightRecorder enables the flight recorder for a set of options and returns it.
+// It returns nil if the options do not enable the flight recorder.
+func optionsFlightRecorder(opts Options) *flightRecorder {
+	if opts.FlightRecorder <= 0 {
+		return nil
+	}
+
+	flight.enable(opts.FlightRecorder)
+	return flight
+}
+
+// enable grows the ring buffer, so it keeps at least the last n entries.
+func (r *flightRecorder) enable(n int) {
+	r.Lock()
+	defer r.Unlock()
+
+	if n <= len(r.entries) {
+		return
+	}
+
+	entries := make([][]interface{}, n)
+	count := copy(entries, r.list())
+	r.entries, r.next, r.count = entries, count%n, count
+}
+
+// record adds an entry to the ring buffer and overwrites the oldest entry if the buffer is full.
+func (r *flightRecorder) record(kv []interface{}) {
+	r.Lock()
+	defer r.Unlock()
+
+	if len(r.entries) == 0 {
+		return
+	}
+
+	r.entries[r.next] = kv
+	r.next = (r.next + 1) % len(r.entries)
+	if r.count < len(r.entries) {
+		r.count++
+	}
+}
+
+// list returns the recorded entries from the oldest to the newest.
+// The lock must be held.
+func (r *flightRecorder) list() [][]interface{} {
+	entries := make([][]interface{}, 0, r.count)
+	for i := 0; i < r.count; i++ {
+		j := (r.next - r.count + i + len(r.entries)) % len(r.entries)
+		entries = append(entries, r.entries[j])
+	}
+
+	return entries
+}
+
+// dump writes the recorded entries to a writer as JSON lines from the oldest to the newest.
+// If reset is true, the recorded entries are removed.
+func (r *flightRecorder) dump(w io.Writer, reset bool) error {
+	r.Lock()
+	entries := r.list()
+	if reset {
+		for i := range r.entries {
+			r.entries[i] = nil
+		}
+		r.next, r.count = 0, 0
+	}
+	r.Unlock()
+
+	var err error
+	logger := kitlog.NewJSONLogger(w)
+	for _, kv := range entries {
+		err = multierr.Append(err, logger.Log(kv...))
+	}
+
+	return err
+}
+
+// DumpFlightRecorder writes the last log entries recorded by the process-wide flight recorder to a writer as JSON lines.
+// The entries of all levels are recorded by the loggers created with Options.FlightRecorder,
+// including the entries not logged because of the logging levels and the entries buffered by tail loggers.
+// The entries are not removed from the flight recorder.
+func DumpFlightRecorder(w io.Writer) error {
+	return flight.dump(w, false)
+}
+
+// DumpFlightRecorderOnPanic dumps the flight recorder to a writer if the goroutine is panicking and then panics again with the same value.
+// It should be deferred at the beginning of a goroutine.
+//
+//	defer log.DumpFlightRecorderOnPanic(os.Stderr)
+func DumpFlightRecorderOnPanic(w io.Writer) {
+	if r := recover(); r != nil {
+		_ = flight.dump(w, false)
+		panic(r)
+	}
+}
+
+// dumpOnFatal writes and removes the recorded entries before the process is exited after a fatal log.
+// The entries are removed, so they are written once by the loggers wrapped in a tee logger.
+func dumpOnFatal(recorder *flightRecorder, o *outputs) {
+	if recorder != nil && o != nil {
+		_ = recorder.dump(o.errOut, true)
+	}
+}
+
+// recordLogger is a go-kit logger that records log entries in a flight recorder.
+type recordLogger struct {
+	recorder *flightRecorder
+}
+
+func (l *recordLogger) Log(kv ...interface{}) error {
+	l.recorder.record(append([]interface{}{}, kv...))
+	return nil
+}
+
+// createRecordLogger creates a go-kit logger that records log entries with the timestamp, the caller, and the given context.
+// The level logger calls it directly instead of through a go-kit level filter, so it skips one stack frame less than the base logger.
+// It returns nil if the flight recorder is not enabled.
+func createRecordLogger(recorder *flightRecorder, depth int, context []interface{}) kitlog.Logger {
+	if recorder == nil {
+		return nil
+	}
+	return createBaseLogger(&recordLogger{recorder}, depth-1, context)
+}
+
+// recordCore is a zap core that records all log entries in a flight recorder regardless of their levels.
+type recordCore struct {
+	recorder *flightRecorder
+	fields   []zapcore.Field
+}
+
+func (c *recordCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *recordCore) With(fields []zapcore.Field) zapcore.Core {
+	return &recordCore{
+		recorder: c.recorder,
+		fields:   append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *recordCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(e, c)
+}
+
+func (c *recordCore) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	kv := []interface{}{
+		"timestamp", e.Time.Format(time.RFC3339Nano),
+		"level", zapLevelName(e.Level),
+	}
+
+	if e.LoggerName != "" {
+		kv = append(kv, "logger", e.LoggerName)
+	}
+
+	if e.Caller.Defined {
+		kv = append(kv, "caller", e.Caller.TrimmedPath())
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		kv = append(kv, k, enc.Fields[k])
+	}
+
+	kv = append(kv, "message", e.Message)
+	if e.Stack != "" {
+		kv = append(kv, "stacktrace", e.Stack)
+	}
+
+	c.recorder.record(kv)
+	return nil
+}
+
+func (c *recordCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,168 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordedCallerRegexp matches the callers of the log entries recorded in this file.
+var recordedCallerRegexp = regexp.MustCompile(`"caller":"[^"]*flightrecorder_test.go:`)
+
+func TestFlightRecorder(t *testing.T) {
+	r := new(flightRecorder)
+
+	// Entries are not recorded before the flight recorder is enabled.
+	r.record([]interface{}{"message", "ignored"})
+	assert.Empty(t, r.list())
+
+	r.enable(2)
+	r.record([]interface{}{"message", "first"})
+	assert.Equal(t, [][]interface{}{{"message", "first"}}, r.list())
+
+	r.record([]interface{}{"message", "second"})
+	r.record([]interface{}{"message", "third"})
+	assert.Equal(t, [][]interface{}{{"message", "second"}, {"message", "third"}}, r.list())
+
+	// The flight recorder only grows and keeps the recorded entries.
+	r.enable(1)
+	r.enable(3)
+	r.record([]interface{}{"message", "fourth"})
+	assert.Equal(t, [][]interface{}{{"message", "second"}, {"message", "third"}, {"message", "fourth"}}, r.list())
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, r.dump(buf, false))
+	assert.Equal(t, "{\"message\":\"second\"}\n{\"message\":\"third\"}\n{\"message\":\"fourth\"}\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, r.dump(buf, true))
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"))
+	assert.Empty(t, r.list())
+
+	r.record([]interface{}{"message", "fifth"})
+	assert.Equal(t, [][]interface{}{{"message", "fifth"}}, r.list())
+}
+
+func TestFlightRecorder_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			flight = new(flightRecorder)
+
+			buf, errBuf := new(bytes.Buffer), new(bytes.Buffer)
+			var code int
+			logger := tc.newLogger(Options{
+				Name:           "my-service",
+				Level:          "info",
+				Writers:        []io.Writer{buf},
+				ErrorWriters:   []io.Writer{errBuf},
+				FlightRecorder: 100,
+				ExitFunc:       func(c int) { code = c },
+			})
+
+			logger.Trace("trace message")
+			logger.Named("db").Debugf("debug message %d", 1)
+			logger.With("key", "value").Info("info message")
+			assert.NotContains(t, buf.String(), "trace message")
+			assert.NotContains(t, buf.String(), "debug message")
+			assert.Contains(t, buf.String(), "info message")
+			assert.False(t, logger.Enabled(LevelDebug))
+
+			// The entries of all levels are recorded.
+			out := new(bytes.Buffer)
+			assert.NoError(t, DumpFlightRecorder(out))
+			assert.Contains(t, out.String(), `"level":"trace"`)
+			assert.Contains(t, out.String(), `"message":"trace message"`)
+			assert.Contains(t, out.String(), `"logger":"my-service.db"`)
+			assert.Contains(t, out.String(), `"message":"debug message 1"`)
+			assert.Contains(t, out.String(), `"key":"value"`)
+			assert.NotContains(t, out.String(), "flightrecorder.go:")
+			assert.Equal(t, strings.Count(out.String(), "\n"), len(recordedCallerRegexp.FindAllString(out.String(), -1)))
+			assert.Less(t, strings.Index(out.String(), "trace message"), strings.Index(out.String(), "info message"))
+
+			// Dumping does not remove the recorded entries.
+			again := new(bytes.Buffer)
+			assert.NoError(t, DumpFlightRecorder(again))
+			assert.Equal(t, out.String(), again.String())
+
+			// The recorded entries are written to the error outputs after a fatal log.
+			logger.Fatal("fatal message")
+			assert.Equal(t, 1, code)
+			assert.Contains(t, errBuf.String(), "trace message")
+			assert.Contains(t, errBuf.String(), "fatal message")
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+
+	for _, tc := range testLoggers {
+		t.Run(tc.name+"Tail", func(t *testing.T) {
+			flight = new(flightRecorder)
+
+			buf := new(bytes.Buffer)
+			logger := NewTailLogger(tc.newLogger(Options{
+				Level:          "info",
+				Writers:        []io.Writer{buf},
+				FlightRecorder: 100,
+			}), TailOptions{Threshold: LevelWarn})
+
+			// The buffered entries are recorded once when they are buffered, even if they are discarded.
+			logger.Info("discarded message")
+			logger.Discard()
+			logger.Info("flushed message", "key", "value")
+			logger.Flush()
+			assert.NotContains(t, buf.String(), "discarded message")
+			assert.Contains(t, buf.String(), "flushed message")
+
+			out := new(bytes.Buffer)
+			assert.NoError(t, DumpFlightRecorder(out))
+			assert.Contains(t, out.String(), "discarded message")
+			assert.Equal(t, strings.Count(out.String(), "discarded message"), strings.Count(out.String(), "flushed message"))
+			assert.Equal(t, strings.Count(out.String(), "\n"), 2*strings.Count(out.String(), "discarded message"))
+			assert.Contains(t, out.String(), `"level":"info"`)
+			assert.Contains(t, out.String(), `"key":"value"`)
+			assert.Equal(t, strings.Count(out.String(), "\n"), len(recordedCallerRegexp.FindAllString(out.String(), -1)))
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		flight = new(flightRecorder)
+
+		logger := NewKit(Options{Level: "info", Writers: []io.Writer{new(bytes.Buffer)}})
+		logger.Debug("debug message")
+		logger.Info("info message")
+
+		out := new(bytes.Buffer)
+		assert.NoError(t, DumpFlightRecorder(out))
+		assert.Empty(t, out.String())
+		assert.NoError(t, logger.Close())
+	})
+}
+
+func TestDumpFlightRecorderOnPanic(t *testing.T) {
+	flight = new(flightRecorder)
+
+	buf := new(bytes.Buffer)
+	logger := NewZap(Options{Level: "error", Writers: []io.Writer{new(bytes.Buffer)}, FlightRecorder: 10})
+	logger.Debug("debug message")
+
+	assert.PanicsWithValue(t, "failure", func() {
+		defer DumpFlightRecorderOnPanic(buf)
+		panic("failure")
+	})
+	assert.Contains(t, buf.String(), "debug message")
+
+	// Nothing is dumped without a panic.
+	buf.Reset()
+	func() {
+		defer DumpFlightRecorderOnPanic(buf)
+	}()
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, logger.Close())
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultForceLevelHeader is the default request header for forcing a logging level.
+const DefaultForceLevelHeader = "X-Log-Level"
+
+// levelForcer is implemented by loggers that can log entries regardless of their logging levels.
+type levelForcer interface {
+	forceLevel(level Level) Logger
+}
+
+// forceLevel returns a copy of a logger that logs the entries in a given level and less verbose levels regardless of its logging level.
+// If the logger does not support forcing a level, the logger itself is returned.
+func forceLevel(l Logger, level Level) Logger {
+	if lf, ok := l.(levelForcer); ok {
+		return lf.forceLevel(level)
+	}
+	return l
+}
+
+type forceLevelKey struct{}
+
+// ForceLevel returns a new context that forces a logging level for the logs of the context.
+// The loggers returned from FromContext and the package-level context functions (DebugContext, etc.)
+// log the entries in the forced level and less verbose levels regardless of their logging levels.
+// This can be used for debugging a single request without changing the logging level of all requests.
+// LevelNone and invalid levels do not force any level.
+func ForceLevel(ctx context.Context, level Level) context.Context {
+	if level == LevelNone || level.validate() != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, forceLevelKey{}, level)
+}
+
+// forcedLevel returns the logging level forced by a context.
+func forcedLevel(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return LevelNone, false
+	}
+
+	level, ok := ctx.Value(forceLevelKey{}).(Level)
+	return level, ok
+}
+
+// withForcedLevel returns a logger that logs in the level forced by a context.
+// If the context does not force a level, the logger itself is returned.
+func withForcedLevel(ctx context.Context, l Logger) Logger {
+	if level, ok := forcedLevel(ctx); ok {
+		return forceLevel(l, level)
+	}
+	return l
+}
+
+// ForceLevelOptions are options for ForceLevelMiddleware.
+type ForceLevelOptions struct {
+	// Header is the request header for forcing a logging level.
+	// The default header is X-Log-Level.
+	Header string
+
+	// Secret is the key for authenticating the header values using HMAC-SHA256.
+	// If it is set, the header values should be created using SignForceLevel and unsigned or expired values are ignored.
+	// If it is not set, the header value is a level name (debug).
+	Secret []byte
+}
+
+// ForceLevelMiddleware returns an HTTP middleware that forces a logging level for a request using a request header (see ForceLevel).
+// Invalid header values are ignored and the request is handled as usual.
+//
+// Without a secret, anyone who can send requests can force a level, so a secret should be set for public endpoints.
+func ForceLevelMiddleware(opts ForceLevelOptions) func(http.Handler) http.Handler {
+	header := opts.Header
+	if header == "" {
+		header = DefaultForceLevelHeader
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if value := r.Header.Get(header); value != "" {
+				if level, ok := parseForceLevel(value, opts.Secret, time.Now()); ok {
+					r = r.WithContext(ForceLevel(r.Context(), level))
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SignForceLevel creates a header value for ForceLevelMiddleware that forces a logging level until a given time.
+// The value has the format level:expiry:signature (debug:1700000000:9f86d0...),
+// where expiry is a Unix time in seconds and signature is the hex-encoded HMAC-SHA256 of level:expiry.
+func SignForceLevel(secret []byte, level Level, expires time.Time) string {
+	payload := level.String() + ":" + strconv.FormatInt(expires.Unix(), 10)
+	return payload + ":" + hex.EncodeToString(forceLevelMAC(secret, payload))
+}
+
+// forceLevelMAC returns the HMAC-SHA256 of a header value payload.
+func forceLevelMAC(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// parseForceLevel parses and authenticates a header value for forcing a logging level.
+// If the secret is empty, the value is a level name.
+func parseForceLevel(value string, secret []byte, now time.Time) (Level, bool) {
+	if len(secret) == 0 {
+		level, err := ParseLevel(value)
+		return level, err == nil
+	}
+
+	i := strings.LastIndex(value, ":")
+	if i < 0 {
+		return LevelNone, false
+	}
+
+	payload, signature := value[:i], value[i+1:]
+	sig, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, forceLevelMAC(secret, payload)) {
+		return LevelNone, false
+	}
+
+	parts := strings.Split(payload, ":")
+	if len(parts) != 2 {
+		return LevelNone, false
+	}
+
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expiry {
+		return LevelNone, false
+	}
+
+	level, err := ParseLevel(parts[0])
+	return level, err == nil
+}
@@ -0,0 +1,290 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForceLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		level         Level
+		expectedLevel Level
+		expectedOK    bool
+	}{
+		{
+			name:          "Debug",
+			ctx:           context.Background(),
+			level:         LevelDebug,
+			expectedLevel: LevelDebug,
+			expectedOK:    true,
+		},
+		{
+			name:          "Override",
+			ctx:           ForceLevel(context.Background(), LevelDebug),
+			level:         LevelTrace,
+			expectedLevel: LevelTrace,
+			expectedOK:    true,
+		},
+		{
+			name:          "None",
+			ctx:           context.Background(),
+			level:         LevelNone,
+			expectedLevel: LevelNone,
+			expectedOK:    false,
+		},
+		{
+			name:          "InvalidLevel",
+			ctx:           context.Background(),
+			level:         Level(6),
+			expectedLevel: LevelNone,
+			expectedOK:    false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := ForceLevel(tc.ctx, tc.level)
+			level, ok := forcedLevel(ctx)
+
+			assert.Equal(t, tc.expectedLevel, level)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
+func TestParseForceLevel(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Unix(1600000000, 0)
+
+	tests := []struct {
+		name          string
+		value         string
+		secret        []byte
+		expectedLevel Level
+		expectedOK    bool
+	}{
+		{
+			name:          "NoSecret",
+			value:         "debug",
+			expectedLevel: LevelDebug,
+			expectedOK:    true,
+		},
+		{
+			name:       "NoSecretInvalidLevel",
+			value:      "verbose",
+			expectedOK: false,
+		},
+		{
+			name:          "Signed",
+			value:         SignForceLevel(secret, LevelTrace, now.Add(time.Minute)),
+			secret:        secret,
+			expectedLevel: LevelTrace,
+			expectedOK:    true,
+		},
+		{
+			name:       "Unsigned",
+			value:      "debug",
+			secret:     secret,
+			expectedOK: false,
+		},
+		{
+			name:       "Expired",
+			value:      SignForceLevel(secret, LevelDebug, now.Add(-time.Second)),
+			secret:     secret,
+			expectedOK: false,
+		},
+		{
+			name:       "WrongSecret",
+			value:      SignForceLevel([]byte("wrong"), LevelDebug, now.Add(time.Minute)),
+			secret:     secret,
+			expectedOK: false,
+		},
+		{
+			name:       "InvalidSignature",
+			value:      "debug:1600000060:xyz",
+			secret:     secret,
+			expectedOK: false,
+		},
+		{
+			name:       "TamperedLevel",
+			value:      strings.Replace(SignForceLevel(secret, LevelDebug, now.Add(time.Minute)), "debug", "trace", 1),
+			secret:     secret,
+			expectedOK: false,
+		},
+		{
+			name:       "SignedInvalidPayload",
+			value:      "debug:" + SignForceLevel(secret, LevelDebug, now.Add(time.Minute)),
+			secret:     secret,
+			expectedOK: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			level, ok := parseForceLevel(tc.value, tc.secret, now)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			if tc.expectedOK {
+				assert.Equal(t, tc.expectedLevel, level)
+			}
+		})
+	}
+}
+
+func TestSignForceLevel(t *testing.T) {
+	value := SignForceLevel([]byte("secret"), LevelDebug, time.Unix(1600000000, 0))
+
+	assert.Equal(t, "debug:1600000000:00957f60e8d1d6159e1989874ebc3ac37cc9c4260bbcf20f0d47985cfe65414d", value)
+}
+
+func TestForceLevelMiddleware(t *testing.T) {
+	secret := []byte("secret")
+
+	tests := []struct {
+		name          string
+		opts          ForceLevelOptions
+		header        string
+		value         string
+		expectedLevel Level
+		expectedOK    bool
+	}{
+		{
+			name:       "NoHeader",
+			opts:       ForceLevelOptions{},
+			expectedOK: false,
+		},
+		{
+			name:          "DefaultHeader",
+			opts:          ForceLevelOptions{},
+			header:        "X-Log-Level",
+			value:         "debug",
+			expectedLevel: LevelDebug,
+			expectedOK:    true,
+		},
+		{
+			name:          "CustomHeader",
+			opts:          ForceLevelOptions{Header: "X-Debug"},
+			header:        "X-Debug",
+			value:         "trace",
+			expectedLevel: LevelTrace,
+			expectedOK:    true,
+		},
+		{
+			name:       "InvalidValue",
+			opts:       ForceLevelOptions{},
+			header:     "X-Log-Level",
+			value:      "verbose",
+			expectedOK: false,
+		},
+		{
+			name:          "Signed",
+			opts:          ForceLevelOptions{Secret: secret},
+			header:        "X-Log-Level",
+			value:         SignForceLevel(secret, LevelDebug, time.Now().Add(time.Minute)),
+			expectedLevel: LevelDebug,
+			expectedOK:    true,
+		},
+		{
+			name:       "Unsigned",
+			opts:       ForceLevelOptions{Secret: secret},
+			header:     "X-Log-Level",
+			value:      "debug",
+			expectedOK: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var level Level
+			var ok bool
+
+			handler := ForceLevelMiddleware(tc.opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				level, ok = forcedLevel(r.Context())
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				r.Header.Set(tc.header, tc.value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tc.expectedOK, ok)
+			if tc.expectedOK {
+				assert.Equal(t, tc.expectedLevel, level)
+			}
+		})
+	}
+}
+
+func TestForceLevel_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Name:    "my-service",
+				Level:   "warn",
+				Writers: []io.Writer{buf},
+			})
+
+			ctx := NewContext(context.Background(), logger)
+			forced := ForceLevel(ctx, LevelDebug)
+
+			DebugContext(ctx, "debug without force")
+			FromContext(ctx).Info("info without force")
+			assert.Empty(t, buf.String())
+
+			DebugContext(forced, "debug context")
+			FromContext(forced).Info("info from context")
+			FromContext(forced).With("key", "value").Named("db").Debug("debug from child")
+			FromContext(forced).Trace("trace from context")
+			assert.Contains(t, buf.String(), "debug context")
+			assert.Contains(t, buf.String(), "info from context")
+			assert.Contains(t, buf.String(), "debug from child")
+			assert.NotContains(t, buf.String(), "trace from context")
+			assert.Contains(t, buf.String(), "force_test.go:")
+			assert.NotContains(t, buf.String(), "context.go:")
+
+			LogContext(ForceLevel(ctx, LevelTrace), LevelTrace, "trace with force")
+			assert.Contains(t, buf.String(), "trace with force")
+
+			assert.True(t, FromContext(forced).Enabled(LevelDebug))
+			assert.False(t, FromContext(forced).Enabled(LevelTrace))
+
+			// The logger itself and its level are not changed.
+			buf.Reset()
+			logger.Debug("debug without context")
+			assert.Empty(t, buf.String())
+			assert.Equal(t, LevelWarn, logger.GetLevel())
+			assert.Equal(t, LevelWarn, FromContext(forced).GetLevel())
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+
+	t.Run("Singleton", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		logger := NewZap(Options{Level: "error", Writers: []io.Writer{buf}})
+		defer ReplaceSingleton(logger)()
+
+		ctx := ForceLevel(context.Background(), LevelInfo)
+		InfoContext(ctx, "info context")
+		DebugContext(ctx, "debug context")
+
+		assert.Contains(t, buf.String(), "info context")
+		assert.NotContains(t, buf.String(), "debug context")
+		assert.Contains(t, buf.String(), "force_test.go:")
+	})
+}
@@ -5,5 +5,6 @@ go 1.15
 require (
 	github.com/go-kit/kit v0.11.0
 	github.com/stretchr/testify v1.7.0
+	go.uber.org/multierr v1.6.0
 	go.uber.org/zap v1.18.1
 )
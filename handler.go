@@ -0,0 +1,188 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// levelRequest is the body of a request for changing a logging level.
+type levelRequest struct {
+	Logger string  `json:"logger"`
+	Level  *string `json:"level"`
+	Revert string  `json:"revert"`
+}
+
+// levelResponse is the body of a response with the current logging levels.
+type levelResponse struct {
+	Level   Level            `json:"level"`
+	Loggers map[string]Level `json:"loggers"`
+}
+
+// errUnknownLogger is the error for changing the level of a named logger that does not exist.
+var errUnknownLogger = errors.New("unknown logger")
+
+// errorResponse is the body of a response for a failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// pendingRevert is a scheduled change of a logging level back to its previous level.
+type pendingRevert struct {
+	timer   *time.Timer
+	restore func()
+}
+
+// levelHandler is an http.Handler for inspecting and changing logging levels.
+type levelHandler struct {
+	sync.Mutex
+	logger  Logger
+	reverts map[string]*pendingRevert
+}
+
+// NewLevelHandler creates an http.Handler for inspecting and changing the logging levels of a logger at runtime.
+// If the logger is nil, the singleton logger is used.
+//
+// A GET request responds with the level of the logger and the levels of the named loggers created from the same root logger.
+//
+//	{"level":"info","loggers":{"db":"debug","http.client":"warn"}}
+//
+// A PUT or POST request changes the level of the logger or a named logger and responds the same as a GET request.
+// The name of a named logger is the name without the name of the root logger (see SetLevelSpec).
+// Only the named loggers that have been created or have a level in the level spec can be changed.
+// If revert is set to a duration, the level is changed back to the previous level after the duration.
+// A named logger that did not have its own level before the change goes back to using the level of its parent.
+// A new change of the same logger cancels a scheduled revert, but the previous level is kept for the new change.
+//
+//	{"logger":"db","level":"debug","revert":"10m"}
+func NewLevelHandler(l Logger) http.Handler {
+	return &levelHandler{
+		logger:  l,
+		reverts: map[string]*pendingRevert{},
+	}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger
+	if logger == nil {
+		logger = Singleton()
+	}
+
+	if logger == nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{"no logger"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		if err := h.change(logger, r); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, errUnknownLogger) {
+				status = http.StatusNotFound
+			}
+			writeJSON(w, status, errorResponse{err.Error()})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{fmt.Sprintf("method %s not allowed", r.Method)})
+		return
+	}
+
+	res := levelResponse{
+		Level:   logger.GetLevel(),
+		Loggers: map[string]Level{},
+	}
+
+	if nl, ok := logger.(namedLeveler); ok {
+		res.Loggers = nl.namedLevels()
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// change changes a logging level as requested.
+func (h *levelHandler) change(logger Logger, r *http.Request) error {
+	var req levelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return fmt.Errorf("invalid request body: %s", err)
+	}
+
+	if req.Level == nil {
+		return errors.New("invalid request body: level is required")
+	}
+
+	// An empty level is parsed as the default level, so it is rejected explicitly.
+	if *req.Level == "" {
+		return errors.New("invalid request body: level cannot be empty")
+	}
+
+	level, err := ParseLevel(*req.Level)
+	if err != nil {
+		return fmt.Errorf("invalid request body: %s", err)
+	}
+
+	var revert time.Duration
+	if req.Revert != "" {
+		d, err := time.ParseDuration(req.Revert)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid revert %q: must be a positive duration", req.Revert)
+		}
+		revert = d
+	}
+
+	// Creating a named logger registers its name, so only the existing names are accepted.
+	target := logger
+	if req.Logger != "" {
+		if nl, ok := logger.(namedLeveler); !ok || !nl.hasNamedLevel(req.Logger) {
+			return fmt.Errorf("%w %q", errUnknownLogger, req.Logger)
+		}
+		target = logger.Named(req.Logger)
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	restore := saveLevel(target)
+	if err := target.SetLevelTo(level); err != nil {
+		return err
+	}
+
+	// A new change cancels reverting a previous change, but the level before the previous change is kept.
+	if p, ok := h.reverts[req.Logger]; ok {
+		p.timer.Stop()
+		restore = p.restore
+		delete(h.reverts, req.Logger)
+	}
+
+	if revert > 0 {
+		p := &pendingRevert{
+			restore: restore,
+		}
+
+		p.timer = time.AfterFunc(revert, func() {
+			h.Lock()
+			defer h.Unlock()
+
+			if h.reverts[req.Logger] == p {
+				p.restore()
+				delete(h.reverts, req.Logger)
+			}
+		})
+
+		h.reverts[req.Logger] = p
+	}
+
+	return nil
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
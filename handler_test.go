@@ -0,0 +1,229 @@
+package log
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelHandler(t *testing.T) {
+	tests := []struct {
+		name               string
+		logger             Logger
+		method             string
+		body               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "NoLogger",
+			logger:             nil,
+			method:             http.MethodGet,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"error":"no logger"}`,
+		},
+		{
+			name:               "MethodNotAllowed",
+			logger:             NewKit(Options{Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodDelete,
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedBody:       `{"error":"method DELETE not allowed"}`,
+		},
+		{
+			name:               "Get",
+			logger:             NewKit(Options{Level: "warn", LevelSpec: "db=debug", Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodGet,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"level":"warn","loggers":{}}`,
+		},
+		{
+			name:               "GetNopLogger",
+			logger:             NewNopLogger(),
+			method:             http.MethodGet,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"level":"none","loggers":{}}`,
+		},
+		{
+			name:               "InvalidBody",
+			logger:             NewZap(Options{Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodPut,
+			body:               `{"level":`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"invalid request body: unexpected EOF"}`,
+		},
+		{
+			name:               "InvalidLevel",
+			logger:             NewZap(Options{Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodPut,
+			body:               `{"level":"verbose"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"invalid request body: invalid level \"verbose\": must be one of trace, debug, info, warn, error, or none"}`,
+		},
+		{
+			name:               "NoLevel",
+			logger:             NewZap(Options{Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodPost,
+			body:               `{"logger":"db"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"invalid request body: level is required"}`,
+		},
+		{
+			name:               "EmptyLevel",
+			logger:             NewZap(Options{Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodPut,
+			body:               `{"level":""}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"invalid request body: level cannot be empty"}`,
+		},
+		{
+			name:               "UnknownLogger",
+			logger:             NewKit(Options{LevelSpec: "db=debug", Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodPost,
+			body:               `{"logger":"zzz","level":"debug"}`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"error":"unknown logger \"zzz\""}`,
+		},
+		{
+			name:               "UnknownLoggerNopLogger",
+			logger:             NewNopLogger(),
+			method:             http.MethodPost,
+			body:               `{"logger":"db","level":"debug"}`,
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"error":"unknown logger \"db\""}`,
+		},
+		{
+			name:               "InvalidRevert",
+			logger:             NewZap(Options{Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodPut,
+			body:               `{"level":"debug","revert":"-1m"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"invalid revert \"-1m\": must be a positive duration"}`,
+		},
+		{
+			name:               "Put",
+			logger:             NewZap(Options{Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodPut,
+			body:               `{"level":"debug"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"level":"debug","loggers":{}}`,
+		},
+		{
+			name:               "PostNamed",
+			logger:             NewKit(Options{Name: "my-service", LevelSpec: "http.client=warn", Writers: []io.Writer{ioutil.Discard}}),
+			method:             http.MethodPost,
+			body:               `{"logger":"http.client","level":"error"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"level":"info","loggers":{"http.client":"error"}}`,
+		},
+		{
+			name: "PostTee",
+			logger: NewTee(
+				NewKit(Options{Level: "error", LevelSpec: "db=info", Writers: []io.Writer{ioutil.Discard}}),
+				NewZap(Options{Level: "warn", Writers: []io.Writer{ioutil.Discard}}),
+			),
+			method:             http.MethodPost,
+			body:               `{"logger":"db","level":"debug"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"level":"warn","loggers":{"db":"debug"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer ReplaceSingleton(nil)()
+
+			r := httptest.NewRequest(tc.method, "/log/level", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			NewLevelHandler(tc.logger).ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestLevelHandler_Singleton(t *testing.T) {
+	defer ReplaceSingleton(nil)()
+
+	h := NewLevelHandler(nil)
+	logger := NewKit(Options{Level: "info", Writers: []io.Writer{ioutil.Discard}})
+	SetSingleton(logger)
+
+	r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"warn"}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, LevelWarn, logger.GetLevel())
+}
+
+func TestLevelHandler_UnknownLogger(t *testing.T) {
+	logger := NewZap(Options{Name: "my-service", Writers: []io.Writer{ioutil.Discard}})
+	h := NewLevelHandler(logger)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"logger":"zzz","level":"debug"}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The unknown name is not registered.
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"info","loggers":{}}`, w.Body.String())
+
+	assert.NoError(t, logger.Close())
+}
+
+func TestLevelHandler_Revert(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := tc.newLogger(Options{
+				Name:    "my-service",
+				Level:   "info",
+				Writers: []io.Writer{ioutil.Discard},
+			})
+			db := logger.Named("db")
+			h := NewLevelHandler(logger)
+
+			put := func(body string) {
+				r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				assert.Equal(t, http.StatusOK, w.Code)
+			}
+
+			put(`{"logger":"db","level":"trace","revert":"1h"}`)
+			put(`{"level":"debug","revert":"20ms"}`)
+			assert.Equal(t, LevelDebug, logger.GetLevel())
+			assert.Equal(t, LevelTrace, db.GetLevel())
+
+			// A new change keeps the level before the first change for reverting.
+			put(`{"logger":"db","level":"error","revert":"20ms"}`)
+			assert.Equal(t, LevelError, db.GetLevel())
+
+			assert.Eventually(t, func() bool {
+				return logger.GetLevel() == LevelInfo && db.GetLevel() == LevelInfo
+			}, time.Second, 5*time.Millisecond)
+
+			// A change without revert cancels a scheduled revert.
+			put(`{"level":"warn","revert":"20ms"}`)
+			put(`{"level":"error"}`)
+			time.Sleep(50 * time.Millisecond)
+			assert.Equal(t, LevelError, logger.GetLevel())
+
+			// A named logger without its own level before the first change goes back to using the level of the root logger.
+			assert.Equal(t, LevelError, db.GetLevel())
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+}
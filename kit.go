@@ -2,44 +2,181 @@ package log
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	kitlog "github.com/go-kit/kit/log"
 	kitlevel "github.com/go-kit/kit/log/level"
+	"go.uber.org/multierr"
 )
 
+const instanceCallerDepth = 7
+
+// Level values of the go-kit log entries not supported by go-kit level package
 const (
-	instanceCallerDepth  = 7
-	singletonCallerDepth = 8
+	kitTraceValue = "trace"
+	kitFatalValue = "fatal"
+	kitPanicValue = "panic"
 )
 
 // kit is an implementation of Logger using go-kit.
+// The level is shared with the loggers created using With and it is stored atomically,
+// so it can be read while it is being changed.
 type kit struct {
-	level  Level
-	base   kitlog.Logger
-	logger *kitlog.SwapLogger
+	name     string
+	depth    int
+	context  []interface{}
+	writer   kitlog.Logger
+	level    *atomicLevel
+	levels   *levelRegistry
+	force    Level
+	base     kitlog.Logger
+	record   kitlog.Logger
+	recorder *flightRecorder
+	logger   kitlog.Logger
+	outputs  *outputs
+	owner    bool
+	exit     func(int)
+}
+
+// errorLogger reports the errors returned from a go-kit logger to an error output.
+type errorLogger struct {
+	logger kitlog.Logger
+	errOut io.Writer
+}
+
+func (l *errorLogger) Log(kv ...interface{}) error {
+	err := l.logger.Log(kv...)
+	if err != nil {
+		fmt.Fprintf(l.errOut, "%v write error: %v\n", time.Now().UTC(), err)
+	}
+	return err
+}
+
+// routeLogger routes log entries to different go-kit loggers based on their levels.
+// Log entries not matching any route are logged using the default logger.
+type routeLogger struct {
+	routes []kitRoute
+	logger kitlog.Logger
+}
+
+type kitRoute struct {
+	Route
+	logger kitlog.Logger
+}
+
+func (l *routeLogger) Log(kv ...interface{}) error {
+	if level, ok := kitEntryLevel(kv); ok {
+		var err error
+		var routed bool
+
+		for _, r := range l.routes {
+			if r.matches(level) {
+				err = multierr.Append(err, r.logger.Log(kv...))
+				routed = true
+			}
+		}
+
+		if routed {
+			return err
+		}
+	}
+
+	return l.logger.Log(kv...)
+}
+
+// asyncLogger queues log entries for being logged asynchronously by a go-kit logger.
+type asyncLogger struct {
+	queue  *asyncQueue
+	logger kitlog.Logger
+}
+
+func (l *asyncLogger) Log(kv ...interface{}) error {
+	level, ok := kitEntryLevel(kv)
+	if !ok {
+		level = LevelInfo
+	}
+
+	// Errors are reported to the error output by the underlying logger.
+	l.queue.push(level, func() {
+		_ = l.logger.Log(kv...)
+	})
+
+	return nil
+}
+
+// kitEntryLevel returns the level of a go-kit log entry.
+// Fatal and panic entries are considered error entries.
+func kitEntryLevel(kv []interface{}) (Level, bool) {
+	for i := 0; i < len(kv)-1; i += 2 {
+		if kv[i] == kitlevel.Key() {
+			switch v := fmt.Sprint(kv[i+1]); v {
+			case kitFatalValue, kitPanicValue:
+				return LevelError, true
+			default:
+				return parseLevel(v), true
+			}
+		}
+	}
+	return LevelNone, false
 }
 
-func createBaseLogger(opts Options) kitlog.Logger {
-	var base kitlog.Logger
+func createFormatLogger(format Format, out, errOut io.Writer) kitlog.Logger {
+	var logger kitlog.Logger
 
-	switch opts.Format {
+	switch format {
 	case FormatConsole:
-		base = kitlog.NewLogfmtLogger(os.Stdout)
+		logger = kitlog.NewLogfmtLogger(out)
 	case FormatJSON:
 		fallthrough
 	default:
-		base = kitlog.NewJSONLogger(os.Stdout)
+		logger = kitlog.NewJSONLogger(out)
 	}
 
-	// This is not required since SwapLogger uses a SyncLogger and can be used concurrently
-	// base = kitlog.NewSyncLogger(base)
+	return &errorLogger{
+		logger: logger,
+		errOut: errOut,
+	}
+}
 
-	context := []interface{}{
-		"timestamp", kitlog.DefaultTimestamp,
-		"caller", kitlog.Caller(instanceCallerDepth),
+// createWriter creates a go-kit logger that formats and writes log entries to the outputs.
+func createWriter(opts Options, outs *outputs) kitlog.Logger {
+	writer := createFormatLogger(opts.Format, outs.main, outs.errOut)
+
+	if len(outs.routes) > 0 {
+		router := &routeLogger{
+			logger: writer,
+		}
+
+		for _, r := range outs.routes {
+			router.routes = append(router.routes, kitRoute{
+				Route:  r.Route,
+				logger: createFormatLogger(opts.Format, r.out, outs.errOut),
+			})
+		}
+
+		writer = router
+	}
+
+	// This is not required since the outputs can be used concurrently
+	// writer = kitlog.NewSyncLogger(writer)
+
+	if outs.async != nil {
+		writer = &asyncLogger{
+			queue:  outs.async,
+			logger: writer,
+		}
 	}
 
+	return writer
+}
+
+// createContext creates the key-value pairs logged by a logger for a set of options.
+func createContext(opts Options) []interface{} {
+	context := []interface{}{}
+
 	if opts.Name != "" {
 		context = append(context, "logger", opts.Name)
 	}
@@ -60,13 +197,26 @@ func createBaseLogger(opts Options) kitlog.Logger {
 		context = append(context, k, v)
 	}
 
-	base = kitlog.With(base, context...)
+	return context
+}
+
+// createBaseLogger creates a go-kit logger that logs the timestamp, the caller, and the given context.
+// depth is the number of stack frames to skip for reporting the caller.
+func createBaseLogger(writer kitlog.Logger, depth int, context []interface{}) kitlog.Logger {
+	kv := []interface{}{
+		"timestamp", kitlog.DefaultTimestamp,
+		"caller", kitlog.Caller(depth),
+	}
+
+	kv = append(kv, context...)
 
-	return base
+	return kitlog.With(writer, kv...)
 }
 
 func createFilteredLogger(base kitlog.Logger, l Level) kitlog.Logger {
 	switch l {
+	case LevelTrace:
+		return kitlevel.NewFilter(base, kitlevel.AllowAll())
 	case LevelDebug:
 		return kitlevel.NewFilter(base, kitlevel.AllowDebug())
 	case LevelInfo:
@@ -82,49 +232,464 @@ func createFilteredLogger(base kitlog.Logger, l Level) kitlog.Logger {
 	}
 }
 
+// levelLogger filters log entries using a logging level that can be changed dynamically.
+// The filtered loggers for all levels are created once, so changing the level does not create new loggers.
+// Entries with levels not supported by go-kit (trace, fatal, and panic) are not filtered and they should be checked by the caller.
+// If the forced level is more verbose than the logging level, entries are filtered using the forced level.
+// If record is set, all entries are recorded before being filtered.
+type levelLogger struct {
+	level   *atomicLevel
+	force   Level
+	base    kitlog.Logger
+	record  kitlog.Logger
+	filters []kitlog.Logger
+}
+
+func newLevelLogger(base, record kitlog.Logger, level *atomicLevel, force Level) *levelLogger {
+	filters := make([]kitlog.Logger, LevelTrace+1)
+	for l := LevelNone; l <= LevelTrace; l++ {
+		filters[l] = createFilteredLogger(base, l)
+	}
+
+	return &levelLogger{
+		level:   level,
+		force:   force,
+		base:    base,
+		record:  record,
+		filters: filters,
+	}
+}
+
+func (l *levelLogger) Log(kv ...interface{}) error {
+	level := l.level.Load()
+	if l.force > level {
+		level = l.force
+	}
+
+	if l.record != nil {
+		_ = l.record.Log(kv...)
+
+		// All entries are passed for being recorded, so the entries in the levels not supported by go-kit are filtered here.
+		if el, ok := kitEntryLevel(kv); ok && el > level {
+			return nil
+		}
+	}
+
+	if level < 0 || int(level) >= len(l.filters) {
+		return nil
+	}
+	return l.filters[level].Log(kv...)
+}
+
 // NewKit creates a new logger based on go-kit logger.
+// Invalid levels are treated as "none".
+// It panics if any of the outputs cannot be opened.
 func NewKit(opts Options) Logger {
-	level := parseLevel(opts.Level)
-	base := createBaseLogger(opts)
-	logger := new(kitlog.SwapLogger)
+	logger, err := newKit(opts)
+	if err != nil {
+		panic(err)
+	}
+	return logger
+}
 
-	filtered := createFilteredLogger(base, level)
-	logger.Swap(filtered)
+// NewKitE creates a new logger based on go-kit logger.
+// Unlike NewKit, it returns an error if the options are invalid or any of the outputs cannot be opened.
+func NewKitE(opts Options) (Logger, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return newKit(opts)
+}
 
-	return &kit{
-		level:  level,
-		base:   base,
-		logger: logger,
+func newKit(opts Options) (Logger, error) {
+	outs, err := openOutputs(opts)
+	if err != nil {
+		return nil, err
 	}
+
+	// Invalid entries of the level spec are ignored like an invalid level.
+	spec, _ := parseLevelSpec(optionsLevelSpec(opts))
+	level := newAtomicLevel(parseLevel(opts.Level))
+	levels := newLevelRegistry(opts.Name, level, spec, func(l Level) levelStore {
+		return newAtomicLevel(l)
+	})
+
+	context := createContext(opts)
+	writer := createWriter(opts, outs)
+	base := createBaseLogger(writer, instanceCallerDepth, context)
+	recorder := optionsFlightRecorder(opts)
+	record := createRecordLogger(recorder, instanceCallerDepth, context)
+
+	return &kit{
+		name:     opts.Name,
+		depth:    instanceCallerDepth,
+		context:  context,
+		writer:   writer,
+		level:    level,
+		levels:   levels,
+		base:     base,
+		record:   record,
+		recorder: recorder,
+		logger:   newLevelLogger(base, record, level, LevelNone),
+		outputs:  outs,
+		owner:    true,
+		exit:     opts.ExitFunc,
+	}, nil
 }
 
 // With returns a new logger that automatically logs the given set of key-value pairs.
 // This can be used for creating a contextualized logger.
+// The new logger shares the logging level with its parent.
 func (k *kit) With(kv ...interface{}) Logger {
+	context := append(k.context[:len(k.context):len(k.context)], kv...)
+	base := createBaseLogger(k.writer, k.depth, context)
+	record := createRecordLogger(k.recorder, k.depth, context)
+
+	return &kit{
+		name:     k.name,
+		depth:    k.depth,
+		context:  context,
+		writer:   k.writer,
+		level:    k.level,
+		levels:   k.levels,
+		force:    k.force,
+		base:     base,
+		record:   record,
+		recorder: k.recorder,
+		logger:   newLevelLogger(base, record, k.level, k.force),
+		outputs:  k.outputs,
+		exit:     k.exit,
+	}
+}
+
+// Named returns a new logger whose name is the name of the logger followed by a dot and the given name.
+// The new logger shares the logging level with the loggers having the same name.
+// Its level is the level of its parent unless it is set by a level spec or by changing the level of the new logger.
+// If the logger is created using WithLevel, the new logger shares the logging level with its parent.
+func (k *kit) Named(name string) Logger {
+	if name == "" {
+		return k
+	}
+
+	fullName := joinName(k.name, name)
+
+	// The name of a named logger is always the first key-value pair of its context (see createContext).
+	context := k.context
+	if k.name != "" {
+		context = context[2:]
+	}
+	context = append([]interface{}{"logger", fullName}, context...)
+	base := createBaseLogger(k.writer, k.depth, context)
+	record := createRecordLogger(k.recorder, k.depth, context)
+
 	level := k.level
-	base := kitlog.With(k.base, kv...)
-	logger := new(kitlog.SwapLogger)
+	if k.levels != nil {
+		level = k.levels.level(fullName).(*atomicLevel)
+	}
+
+	return &kit{
+		name:     fullName,
+		depth:    k.depth,
+		context:  context,
+		writer:   k.writer,
+		level:    level,
+		levels:   k.levels,
+		force:    k.force,
+		base:     base,
+		record:   record,
+		recorder: k.recorder,
+		logger:   newLevelLogger(base, record, level, k.force),
+		outputs:  k.outputs,
+		exit:     k.exit,
+	}
+}
 
-	filtered := createFilteredLogger(base, level)
-	logger.Swap(filtered)
+// WithLevel returns a new logger that has its own logging level independent of its parent.
+// The loggers created from the new logger using With share the new logging level.
+// Invalid levels are treated as "none".
+func (k *kit) WithLevel(level Level) Logger {
+	if level.validate() != nil {
+		level = LevelNone
+	}
+
+	l := newAtomicLevel(level)
 
 	return &kit{
-		level:  level,
-		base:   base,
-		logger: logger,
+		name:     k.name,
+		depth:    k.depth,
+		context:  k.context,
+		writer:   k.writer,
+		level:    l,
+		force:    k.force,
+		base:     k.base,
+		record:   k.record,
+		recorder: k.recorder,
+		logger:   newLevelLogger(k.base, k.record, l, k.force),
+		outputs:  k.outputs,
+		exit:     k.exit,
+	}
+}
+
+// addCallerSkip returns a copy of the logger that skips extra stack frames for reporting the caller.
+func (k *kit) addCallerSkip(skip int) Logger {
+	depth := k.depth + skip
+	base := createBaseLogger(k.writer, depth, k.context)
+	record := createRecordLogger(k.recorder, depth, k.context)
+
+	return &kit{
+		name:     k.name,
+		depth:    depth,
+		context:  k.context,
+		writer:   k.writer,
+		level:    k.level,
+		levels:   k.levels,
+		force:    k.force,
+		base:     base,
+		record:   record,
+		recorder: k.recorder,
+		logger:   newLevelLogger(base, record, k.level, k.force),
+		outputs:  k.outputs,
+		owner:    k.owner,
+		exit:     k.exit,
+	}
+}
+
+// exitFunc returns the function called for exiting the process after fatal logs.
+func (k *kit) exitFunc() func(int) {
+	return exitFunc(k.exit)
+}
+
+// withExitFunc returns a copy of the logger that calls the given function for exiting the process after fatal logs.
+func (k *kit) withExitFunc(exit func(int)) Logger {
+	return &kit{
+		name:     k.name,
+		depth:    k.depth,
+		context:  k.context,
+		writer:   k.writer,
+		level:    k.level,
+		levels:   k.levels,
+		force:    k.force,
+		base:     k.base,
+		record:   k.record,
+		recorder: k.recorder,
+		logger:   k.logger,
+		outputs:  k.outputs,
+		owner:    k.owner,
+		exit:     exit,
 	}
 }
 
 // GetLevel returns the current logging level.
 func (k *kit) GetLevel() Level {
-	return k.level
+	return k.level.Load()
 }
 
-// SetLevel changes the logging level.
+// SetLevel changes the logging level of the logger and all loggers sharing the same level.
+// The named loggers created from the logger that do not have their own levels are changed too.
+// Invalid level names are ignored.
 func (k *kit) SetLevel(level string) {
-	k.level = parseLevel(level)
-	filtered := createFilteredLogger(k.base, k.level)
-	k.logger.Swap(filtered)
+	if l, err := ParseLevel(level); err == nil {
+		k.storeLevel(l)
+	}
+}
+
+// SetLevelTo changes the logging level of the logger and all loggers sharing the same level.
+// The named loggers created from the logger that do not have their own levels are changed too.
+// It returns an error if the level is invalid.
+func (k *kit) SetLevelTo(level Level) error {
+	if err := level.validate(); err != nil {
+		return err
+	}
+
+	k.storeLevel(level)
+	return nil
+}
+
+// SetLevelFor changes the logging level of the logger and all loggers sharing the same level for a duration.
+// It returns a function for changing the level back before the duration ends.
+func (k *kit) SetLevelFor(level Level, d time.Duration) (func(), error) {
+	return setLevelFor(k, k.level, level, d)
+}
+
+// storeLevel changes the logging level of the logger and the named loggers under it that do not have their own levels.
+func (k *kit) storeLevel(level Level) {
+	if k.levels != nil {
+		k.levels.set(k.name, level)
+	} else {
+		k.level.Store(level)
+	}
+}
+
+// saveLevel returns a function that changes the logging level back to the current level.
+// If the named logger does not have its own level, the function removes its level instead.
+func (k *kit) saveLevel() func() {
+	if k.levels != nil && !k.levels.hasRule(k.name) {
+		return func() {
+			k.levels.unset(k.name)
+		}
+	}
+
+	level := k.GetLevel()
+	return func() {
+		k.storeLevel(level)
+	}
+}
+
+// namedLevels returns the levels of the named loggers created from the same root logger.
+func (k *kit) namedLevels() map[string]Level {
+	if k.levels != nil {
+		return k.levels.list()
+	}
+	return map[string]Level{}
+}
+
+// hasNamedLevel determines whether or not a named logger under the logger has been created or has a level in the level spec.
+func (k *kit) hasNamedLevel(name string) bool {
+	return k.levels != nil && k.levels.has(joinName(k.name, name))
+}
+
+// setLevelSpec changes the logging levels of all loggers created from the same root logger using a level spec.
+func (k *kit) setLevelSpec(spec levelSpec) {
+	if k.levels != nil {
+		k.levels.apply(spec)
+	}
+}
+
+// forceLevel returns a copy of the logger that logs the entries in a given level and less verbose levels regardless of its logging level.
+func (k *kit) forceLevel(level Level) Logger {
+	if level <= k.force {
+		return k
+	}
+
+	return &kit{
+		name:     k.name,
+		depth:    k.depth,
+		context:  k.context,
+		writer:   k.writer,
+		level:    k.level,
+		levels:   k.levels,
+		force:    level,
+		base:     k.base,
+		record:   k.record,
+		recorder: k.recorder,
+		logger:   newLevelLogger(k.base, k.record, k.level, level),
+		outputs:  k.outputs,
+		exit:     k.exit,
+	}
+}
+
+// asyncStats returns the counters of the logger if it logs asynchronously.
+func (k *kit) asyncStats() AsyncStats {
+	if k.outputs != nil && k.outputs.async != nil {
+		return k.outputs.async.stats()
+	}
+	return AsyncStats{}
+}
+
+// Enabled determines whether or not the logger logs in a given level.
+func (k *kit) Enabled(level Level) bool {
+	return level > LevelNone && level <= LevelTrace && level <= k.currentLevel()
+}
+
+// passes determines whether or not the entries in a given level are passed to the level logger.
+// All entries are passed if the flight recorder is enabled, so they are recorded regardless of the logging level.
+func (k *kit) passes(level Level) bool {
+	return k.recorder != nil || level <= k.currentLevel()
+}
+
+// currentLevel returns the most verbose of the logging level and the forced level.
+func (k *kit) currentLevel() Level {
+	if level := k.level.Load(); level > k.force {
+		return level
+	}
+	return k.force
+}
+
+// leveled returns a go-kit logger that logs in a given level.
+// It returns false if the entries in the level are not passed to the level logger.
+func (k *kit) leveled(level Level) (kitlog.Logger, bool) {
+	if level <= LevelNone || level > LevelTrace || !k.passes(level) {
+		return nil, false
+	}
+
+	return kitlog.WithPrefix(k.logger, kitlevel.Key(), kitLevelValue(level)), true
+}
+
+// kitLevelValue returns the go-kit level value of a logging level.
+func kitLevelValue(level Level) interface{} {
+	switch level {
+	case LevelError:
+		return kitlevel.ErrorValue()
+	case LevelWarn:
+		return kitlevel.WarnValue()
+	case LevelInfo:
+		return kitlevel.InfoValue()
+	case LevelDebug:
+		return kitlevel.DebugValue()
+	default:
+		return kitTraceValue
+	}
+}
+
+// logEntry logs an entry recorded earlier with its own timestamp and caller regardless of the logging level.
+// The entry is not recorded in the flight recorder, since it is recorded when it is buffered.
+func (k *kit) logEntry(e entry) {
+	k.writeEntry(k.writer, e)
+}
+
+// recordEntry records an entry buffered for being logged later in the flight recorder.
+func (k *kit) recordEntry(e entry) {
+	if k.recorder != nil {
+		k.writeEntry(&recordLogger{k.recorder}, e)
+	}
+}
+
+// writeEntry writes an entry recorded earlier with its own timestamp and caller to a go-kit logger.
+func (k *kit) writeEntry(w kitlog.Logger, e entry) {
+	if e.level <= LevelNone || e.level > LevelTrace {
+		return
+	}
+
+	kv := []interface{}{
+		"timestamp", kitlog.TimestampFormat(func() time.Time { return e.time }, time.RFC3339Nano)(),
+		"caller", filepath.Base(e.file) + ":" + strconv.Itoa(e.line),
+	}
+
+	kv = append(kv, k.context...)
+	logger := kitlog.WithPrefix(kitlog.With(w, kv...), kitlevel.Key(), kitLevelValue(e.level))
+	_ = logger.Log(append(e.kv[:len(e.kv):len(e.kv)], "message", e.message)...)
+}
+
+// Log logs a message and a list of key-value pairs in a given level.
+func (k *kit) Log(level Level, message string, kv ...interface{}) {
+	if logger, ok := k.leveled(level); ok {
+		kv = append(kv, "message", message)
+		_ = logger.Log(kv...)
+	}
+}
+
+// Logf formats and logs a message in a given level.
+// It uses fmt.Sprintf() to log a message.
+func (k *kit) Logf(level Level, format string, v ...interface{}) {
+	if logger, ok := k.leveled(level); ok {
+		_ = logger.Log("message", fmt.Sprintf(format, v...))
+	}
+}
+
+// Trace logs a message and a list of key-value pairs in trace level.
+func (k *kit) Trace(message string, kv ...interface{}) {
+	if k.passes(LevelTrace) {
+		kv = append(kv, "message", message)
+		_ = kitlog.WithPrefix(k.logger, kitlevel.Key(), kitTraceValue).Log(kv...)
+	}
+}
+
+// Tracef formats and logs a message in trace level.
+// It uses fmt.Sprintf() to log a message.
+func (k *kit) Tracef(format string, v ...interface{}) {
+	if k.passes(LevelTrace) {
+		_ = kitlog.WithPrefix(k.logger, kitlevel.Key(), kitTraceValue).Log("message", fmt.Sprintf(format, v...))
+	}
 }
 
 // Debug logs a message and a list of key-value pairs in debug level.
@@ -175,7 +740,69 @@ func (k *kit) Errorf(format string, v ...interface{}) {
 	_ = kitlevel.Error(k.logger).Log("message", fmt.Sprintf(format, v...))
 }
 
-// Close flushes the logger.
+// Fatal logs a message and a list of key-value pairs in fatal level.
+// It then flushes the logger and exits the process.
+func (k *kit) Fatal(message string, kv ...interface{}) {
+	if k.passes(LevelError) {
+		kv = append(kv, "message", message)
+		_ = kitlog.WithPrefix(k.logger, kitlevel.Key(), kitFatalValue).Log(kv...)
+	}
+	k.flush()
+	dumpOnFatal(k.recorder, k.outputs)
+	k.exitFunc()(1)
+}
+
+// Fatalf formats and logs a message in fatal level.
+// It uses fmt.Sprintf() to log a message.
+// It then flushes the logger and exits the process.
+func (k *kit) Fatalf(format string, v ...interface{}) {
+	if k.passes(LevelError) {
+		_ = kitlog.WithPrefix(k.logger, kitlevel.Key(), kitFatalValue).Log("message", fmt.Sprintf(format, v...))
+	}
+	k.flush()
+	dumpOnFatal(k.recorder, k.outputs)
+	k.exitFunc()(1)
+}
+
+// Panic logs a message and a list of key-value pairs in panic level.
+// It then flushes the logger and panics with the message.
+func (k *kit) Panic(message string, kv ...interface{}) {
+	if k.passes(LevelError) {
+		kv = append(kv, "message", message)
+		_ = kitlog.WithPrefix(k.logger, kitlevel.Key(), kitPanicValue).Log(kv...)
+	}
+	k.flush()
+	panic(message)
+}
+
+// Panicf formats and logs a message in panic level.
+// It uses fmt.Sprintf() to log a message.
+// It then flushes the logger and panics with the message.
+func (k *kit) Panicf(format string, v ...interface{}) {
+	message := fmt.Sprintf(format, v...)
+	if k.passes(LevelError) {
+		_ = kitlog.WithPrefix(k.logger, kitlevel.Key(), kitPanicValue).Log("message", message)
+	}
+	k.flush()
+	panic(message)
+}
+
+// flush writes all queued and buffered logs to the outputs.
+func (k *kit) flush() {
+	if k.outputs != nil {
+		_ = k.outputs.Sync()
+	}
+}
+
+// Close flushes the logger and returns the errors of writing the buffered logs.
+// If the logger is not created using With, its outputs are closed too.
 func (k *kit) Close() error {
-	return nil
+	if k.outputs == nil {
+		return nil
+	}
+
+	if k.owner {
+		return k.outputs.Close()
+	}
+	return k.outputs.Sync()
 }
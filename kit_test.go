@@ -1,10 +1,14 @@
 package log
 
 import (
+	"bytes"
 	"errors"
+	"io"
+	"io/ioutil"
 	"testing"
 
 	kitlog "github.com/go-kit/kit/log"
+	kitlevel "github.com/go-kit/kit/log/level"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,7 +23,106 @@ func (m *mockKitLogger) Log(kv ...interface{}) error {
 	return m.LogOutError
 }
 
-func TestCreateBaseLogger(t *testing.T) {
+func TestErrorLogger(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockKitLogger *mockKitLogger
+		kv            []interface{}
+		expectedError error
+		expectedOut   string
+	}{
+		{
+			name:          "Success",
+			mockKitLogger: &mockKitLogger{},
+			kv:            []interface{}{"message", "operation succeeded"},
+			expectedError: nil,
+			expectedOut:   "",
+		},
+		{
+			name: "Error",
+			mockKitLogger: &mockKitLogger{
+				LogOutError: errors.New("log error"),
+			},
+			kv:            []interface{}{"message", "operation failed"},
+			expectedError: errors.New("log error"),
+			expectedOut:   "write error: log error\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errOut := new(bytes.Buffer)
+			logger := &errorLogger{
+				logger: tc.mockKitLogger,
+				errOut: errOut,
+			}
+
+			err := logger.Log(tc.kv...)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.kv, tc.mockKitLogger.LogInKV)
+			assert.Contains(t, errOut.String(), tc.expectedOut)
+		})
+	}
+}
+
+func TestRouteLogger(t *testing.T) {
+	tests := []struct {
+		name          string
+		kv            []interface{}
+		expectedRoute int
+		expectedError error
+	}{
+		{
+			name:          "NoLevel",
+			kv:            []interface{}{"message", "no level"},
+			expectedRoute: -1,
+		},
+		{
+			name:          "NotRouted",
+			kv:            []interface{}{"level", kitlevel.InfoValue(), "message", "info"},
+			expectedRoute: -1,
+		},
+		{
+			name:          "Warn",
+			kv:            []interface{}{"level", kitlevel.WarnValue(), "message", "warn"},
+			expectedRoute: 0,
+		},
+		{
+			name:          "Error",
+			kv:            []interface{}{"level", kitlevel.ErrorValue(), "message", "error"},
+			expectedRoute: 0,
+			expectedError: errors.New("log error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			routed := &mockKitLogger{LogOutError: tc.expectedError}
+			unrouted := &mockKitLogger{}
+
+			logger := &routeLogger{
+				routes: []kitRoute{
+					{Route: Route{From: LevelWarn, To: LevelError}, logger: routed},
+				},
+				logger: unrouted,
+			}
+
+			err := logger.Log(tc.kv...)
+
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedRoute == 0 {
+				assert.Equal(t, tc.kv, routed.LogInKV)
+				assert.Nil(t, unrouted.LogInKV)
+			} else {
+				assert.Nil(t, routed.LogInKV)
+				assert.Equal(t, tc.kv, unrouted.LogInKV)
+			}
+		})
+	}
+}
+
+func TestCreateWriter(t *testing.T) {
 	tests := []struct {
 		name string
 		opts Options
@@ -52,13 +155,108 @@ func TestCreateBaseLogger(t *testing.T) {
 				Format: FormatConsole,
 			},
 		},
+		{
+			"Routes",
+			Options{
+				Routes: []Route{
+					{From: LevelWarn, To: LevelError},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			outs := &outputs{
+				main:   &output{writers: []io.Writer{ioutil.Discard}},
+				errOut: &output{writers: []io.Writer{ioutil.Discard}},
+			}
+
+			for _, r := range tc.opts.Routes {
+				outs.routes = append(outs.routes, route{
+					Route: r,
+					out:   &output{writers: []io.Writer{ioutil.Discard}},
+				})
+			}
+
+			writer := createWriter(tc.opts, outs)
+
+			assert.NotNil(t, writer)
+		})
+	}
+}
+
+func TestCreateContext(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            Options
+		expectedContext []interface{}
+	}{
+		{
+			name:            "Default",
+			opts:            Options{},
+			expectedContext: []interface{}{},
+		},
+		{
+			name: "Production",
+			opts: Options{
+				Name:        "my-service",
+				Version:     "0.1.0",
+				Environment: "production",
+				Region:      "ca-central-1",
+				Tags: map[string]string{
+					"domain": "auth",
+				},
+			},
+			expectedContext: []interface{}{
+				"logger", "my-service",
+				"version", "0.1.0",
+				"environment", "production",
+				"region", "ca-central-1",
+				"domain", "auth",
+			},
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			base := createBaseLogger(tc.opts)
+			context := createContext(tc.opts)
 
-			assert.NotNil(t, base)
+			assert.Equal(t, tc.expectedContext, context)
+		})
+	}
+}
+
+func TestCreateBaseLogger(t *testing.T) {
+	tests := []struct {
+		name       string
+		depth      int
+		context    []interface{}
+		expectedKV []interface{}
+	}{
+		{
+			name:       "NoContext",
+			depth:      instanceCallerDepth,
+			context:    nil,
+			expectedKV: []interface{}{"timestamp", "caller", "message", "test"},
+		},
+		{
+			name:       "WithContext",
+			depth:      instanceCallerDepth + 1,
+			context:    []interface{}{"logger", "my-service"},
+			expectedKV: []interface{}{"timestamp", "caller", "logger", "my-service", "message", "test"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			writer := &mockKitLogger{}
+			base := createBaseLogger(writer, tc.depth, tc.context)
+
+			assert.NoError(t, base.Log("message", "test"))
+			for _, val := range tc.expectedKV {
+				assert.Contains(t, writer.LogInKV, val)
+			}
 		})
 	}
 }
@@ -110,6 +308,40 @@ func TestCreateFilteredLogger(t *testing.T) {
 	}
 }
 
+func TestLevelLogger(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         Level
+		force         Level
+		expectedCalls []bool
+	}{
+		{"None", LevelNone, LevelNone, []bool{false, false, false, false}},
+		{"Error", LevelError, LevelNone, []bool{false, false, false, true}},
+		{"Warn", LevelWarn, LevelNone, []bool{false, false, true, true}},
+		{"Info", LevelInfo, LevelNone, []bool{false, true, true, true}},
+		{"Debug", LevelDebug, LevelNone, []bool{true, true, true, true}},
+		{"ForcedDebug", LevelWarn, LevelDebug, []bool{true, true, true, true}},
+		{"ForcedLessVerbose", LevelInfo, LevelError, []bool{false, true, true, true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			base := &mockKitLogger{}
+			level := new(atomicLevel)
+			logger := newLevelLogger(base, nil, level, tc.force)
+
+			// The level is changed after creating the logger.
+			level.Store(tc.level)
+
+			for i, lf := range []func(kitlog.Logger) kitlog.Logger{kitlevel.Debug, kitlevel.Info, kitlevel.Warn, kitlevel.Error} {
+				base.LogInKV = nil
+				_ = lf(logger).Log("message", "test")
+				assert.Equal(t, tc.expectedCalls[i], base.LogInKV != nil)
+			}
+		})
+	}
+}
+
 func TestNewKit(t *testing.T) {
 	tests := []struct {
 		name string
@@ -190,6 +422,108 @@ func TestNewKit(t *testing.T) {
 	}
 }
 
+func TestNewKitE(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          Options
+		expectedError string
+	}{
+		{
+			name: "Default",
+			opts: Options{},
+		},
+		{
+			name: "Production",
+			opts: Options{
+				Name:    "my-service",
+				Version: "0.1.0",
+				Level:   "Warn",
+				Format:  FormatConsole,
+			},
+		},
+		{
+			name:          "InvalidLevel",
+			opts:          Options{Level: "warning"},
+			expectedError: `invalid level "warning": must be one of trace, debug, info, warn, error, or none`,
+		},
+		{
+			name:          "InvalidOutputPath",
+			opts:          Options{OutputPaths: []string{"http://localhost"}},
+			expectedError: `invalid output path "http://localhost": unsupported scheme "http"`,
+		},
+		{
+			name:          "OutputPathNotOpened",
+			opts:          Options{OutputPaths: []string{"/missing/directory/app.log"}},
+			expectedError: "open /missing/directory/app.log: no such file or directory",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger, err := NewKitE(tc.opts)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, logger)
+			} else {
+				assert.NoError(t, err)
+				assert.IsType(t, &kit{}, logger)
+				assert.Equal(t, parseLevel(tc.opts.Level), logger.GetLevel())
+				assert.NoError(t, logger.Close())
+			}
+		})
+	}
+}
+
+func TestKitOutputs(t *testing.T) {
+	tests := []struct {
+		name           string
+		writer         *mockWriter
+		message        string
+		expectedOut    string
+		expectedErrOut string
+	}{
+		{
+			name:           "Success",
+			writer:         &mockWriter{WriteOutN: 1024},
+			message:        "operation succeeded",
+			expectedOut:    `"message":"operation succeeded"`,
+			expectedErrOut: "",
+		},
+		{
+			name:           "WriteError",
+			writer:         &mockWriter{WriteOutError: errors.New("disk full")},
+			message:        "operation failed",
+			expectedOut:    `"message":"operation failed"`,
+			expectedErrOut: "write error: disk full",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+			logger := NewKit(Options{
+				Writers:      []io.Writer{out, tc.writer},
+				ErrorWriters: []io.Writer{errOut},
+			})
+
+			logger.Info(tc.message)
+
+			assert.Contains(t, out.String(), tc.expectedOut)
+			assert.Contains(t, string(tc.writer.WriteInP), tc.expectedOut)
+			assert.Contains(t, errOut.String(), tc.expectedErrOut)
+		})
+	}
+
+	t.Run("InvalidOutputPath", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewKit(Options{
+				OutputPaths: []string{"http://localhost"},
+			})
+		})
+	})
+}
+
 func TestKitWith(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -199,9 +533,11 @@ func TestKitWith(t *testing.T) {
 		{
 			"OK",
 			&kit{
-				level:  LevelInfo,
+				depth:  instanceCallerDepth,
+				writer: kitlog.NewNopLogger(),
+				level:  newAtomicLevel(LevelInfo),
 				base:   kitlog.NewNopLogger(),
-				logger: &kitlog.SwapLogger{},
+				logger: kitlog.NewNopLogger(),
 			},
 			[]interface{}{
 				"version", "0.1.0",
@@ -221,6 +557,27 @@ func TestKitWith(t *testing.T) {
 	}
 }
 
+func TestKitWithLevel(t *testing.T) {
+	level := newAtomicLevel(LevelInfo)
+	logger := &kit{
+		depth:  instanceCallerDepth,
+		writer: kitlog.NewNopLogger(),
+		level:  level,
+		base:   kitlog.NewNopLogger(),
+		logger: kitlog.NewNopLogger(),
+	}
+
+	child := logger.WithLevel(LevelDebug)
+
+	assert.IsType(t, &kit{}, child)
+	assert.Equal(t, LevelDebug, child.GetLevel())
+	assert.NotSame(t, level, child.(*kit).level)
+
+	child.SetLevel("error")
+	assert.Equal(t, LevelError, child.GetLevel())
+	assert.Equal(t, LevelInfo, logger.GetLevel())
+}
+
 func TestKitGetLevel(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -230,35 +587,35 @@ func TestKitGetLevel(t *testing.T) {
 		{
 			"None",
 			&kit{
-				level: LevelNone,
+				level: newAtomicLevel(LevelNone),
 			},
 			LevelNone,
 		},
 		{
 			"Error",
 			&kit{
-				level: LevelError,
+				level: newAtomicLevel(LevelError),
 			},
 			LevelError,
 		},
 		{
 			"Warn",
 			&kit{
-				level: LevelWarn,
+				level: newAtomicLevel(LevelWarn),
 			},
 			LevelWarn,
 		},
 		{
 			"Info",
 			&kit{
-				level: LevelInfo,
+				level: newAtomicLevel(LevelInfo),
 			},
 			LevelInfo,
 		},
 		{
 			"Debug",
 			&kit{
-				level: LevelDebug,
+				level: newAtomicLevel(LevelDebug),
 			},
 			LevelDebug,
 		},
@@ -283,8 +640,9 @@ func TestKitSetLevel(t *testing.T) {
 		{
 			"None",
 			&kit{
+				level:  new(atomicLevel),
 				base:   kitlog.NewNopLogger(),
-				logger: &kitlog.SwapLogger{},
+				logger: kitlog.NewNopLogger(),
 			},
 			"none",
 			LevelNone,
@@ -292,8 +650,9 @@ func TestKitSetLevel(t *testing.T) {
 		{
 			"Error",
 			&kit{
+				level:  new(atomicLevel),
 				base:   kitlog.NewNopLogger(),
-				logger: &kitlog.SwapLogger{},
+				logger: kitlog.NewNopLogger(),
 			},
 			"error",
 			LevelError,
@@ -301,8 +660,9 @@ func TestKitSetLevel(t *testing.T) {
 		{
 			"Warn",
 			&kit{
+				level:  new(atomicLevel),
 				base:   kitlog.NewNopLogger(),
-				logger: &kitlog.SwapLogger{},
+				logger: kitlog.NewNopLogger(),
 			},
 			"warn",
 			LevelWarn,
@@ -310,8 +670,9 @@ func TestKitSetLevel(t *testing.T) {
 		{
 			"Info",
 			&kit{
+				level:  new(atomicLevel),
 				base:   kitlog.NewNopLogger(),
-				logger: &kitlog.SwapLogger{},
+				logger: kitlog.NewNopLogger(),
 			},
 			"info",
 			LevelInfo,
@@ -319,19 +680,40 @@ func TestKitSetLevel(t *testing.T) {
 		{
 			"Debug",
 			&kit{
+				level:  new(atomicLevel),
 				base:   kitlog.NewNopLogger(),
-				logger: &kitlog.SwapLogger{},
+				logger: kitlog.NewNopLogger(),
 			},
 			"debug",
 			LevelDebug,
 		},
+		{
+			"Trace",
+			&kit{
+				level:  new(atomicLevel),
+				base:   kitlog.NewNopLogger(),
+				logger: kitlog.NewNopLogger(),
+			},
+			"trace",
+			LevelTrace,
+		},
+		{
+			"Invalid",
+			&kit{
+				level:  newAtomicLevel(LevelInfo),
+				base:   kitlog.NewNopLogger(),
+				logger: kitlog.NewNopLogger(),
+			},
+			"invalid",
+			LevelInfo,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.logger.SetLevel(tc.level)
 
-			assert.Equal(t, tc.expectedLevel, tc.logger.level)
+			assert.Equal(t, tc.expectedLevel, tc.logger.level.Load())
 		})
 	}
 }
@@ -364,8 +746,7 @@ func TestKitLog(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			kl := &kit{logger: &kitlog.SwapLogger{}}
-			kl.logger.Swap(tc.mockKitLogger)
+			kl := &kit{logger: tc.mockKitLogger}
 
 			t.Run("Debug", func(t *testing.T) {
 				kl.Debug(tc.message, tc.kv...)
@@ -426,8 +807,7 @@ func TestKitLogf(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			kl := &kit{logger: &kitlog.SwapLogger{}}
-			kl.logger.Swap(tc.mockKitLogger)
+			kl := &kit{logger: tc.mockKitLogger}
 
 			t.Run("Debugf", func(t *testing.T) {
 				kl.Debugf(tc.format, tc.args...)
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// LevelSignalOptions are configurations for changing the logging level of a logger using signals.
+//
+// Receiving the Verbose signal (SIGUSR1 by default) makes the level one step more verbose up to MaxLevel (LevelDebug by default).
+// Receiving the Quiet signal (SIGUSR2 by default) makes the level one step less verbose down to LevelError.
+type LevelSignalOptions struct {
+	Verbose  os.Signal
+	Quiet    os.Signal
+	MaxLevel Level
+}
+
+// levelSignals changes the logging level of a logger when the process receives signals.
+type levelSignals struct {
+	logger  Logger
+	opts    LevelSignalOptions
+	signals chan os.Signal
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+func newLevelSignals(l Logger, opts LevelSignalOptions) *levelSignals {
+	if opts.Verbose == nil {
+		opts.Verbose = defaultVerboseSignal
+	}
+
+	if opts.Quiet == nil {
+		opts.Quiet = defaultQuietSignal
+	}
+
+	if opts.MaxLevel == LevelNone {
+		opts.MaxLevel = LevelDebug
+	}
+
+	s := &levelSignals{
+		logger:  l,
+		opts:    opts,
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+// HandleLevelSignals changes the logging level of a logger when the process receives signals (see LevelSignalOptions).
+// If the logger is nil, the level of the singleton logger is changed.
+// Every change is logged using the logger itself.
+//
+// It returns a function for stopping handling the signals.
+func HandleLevelSignals(l Logger, opts LevelSignalOptions) (stop func()) {
+	s := newLevelSignals(l, opts)
+
+	var sigs []os.Signal
+	for _, sig := range []os.Signal{s.opts.Verbose, s.opts.Quiet} {
+		if sig != nil {
+			sigs = append(sigs, sig)
+		}
+	}
+
+	if len(sigs) > 0 {
+		signal.Notify(s.signals, sigs...)
+	}
+
+	return s.stop
+}
+
+func (s *levelSignals) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case sig := <-s.signals:
+			s.handle(sig)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// handle changes the logging level for a signal.
+func (s *levelSignals) handle(sig os.Signal) {
+	logger := s.logger
+	if logger == nil {
+		logger = Singleton()
+	}
+
+	if logger == nil {
+		return
+	}
+
+	from := logger.GetLevel()
+	to := from
+
+	switch sig {
+	case s.opts.Verbose:
+		if from < s.opts.MaxLevel {
+			to = from + 1
+		}
+	case s.opts.Quiet:
+		if from > LevelError {
+			to = from - 1
+		}
+	}
+
+	if to == from {
+		return
+	}
+
+	if err := logger.SetLevelTo(to); err != nil {
+		return
+	}
+
+	logLevelChange(logger, from, to, "logging level changed", "signal", sig.String())
+}
+
+// stop stops handling the signals and waits for the current signal to be handled.
+func (s *levelSignals) stop() {
+	s.once.Do(func() {
+		signal.Stop(s.signals)
+		close(s.done)
+		s.wg.Wait()
+	})
+}
@@ -0,0 +1,151 @@
+package log
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSignal is a synthetic signal for testing.
+type testSignal string
+
+func (s testSignal) Signal()        {}
+func (s testSignal) String() string { return string(s) }
+
+func TestNewLevelSignals(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         LevelSignalOptions
+		expectedOpts LevelSignalOptions
+	}{
+		{
+			name: "Default",
+			opts: LevelSignalOptions{},
+			expectedOpts: LevelSignalOptions{
+				Verbose:  defaultVerboseSignal,
+				Quiet:    defaultQuietSignal,
+				MaxLevel: LevelDebug,
+			},
+		},
+		{
+			name: "Custom",
+			opts: LevelSignalOptions{
+				Verbose:  testSignal("verbose"),
+				Quiet:    testSignal("quiet"),
+				MaxLevel: LevelTrace,
+			},
+			expectedOpts: LevelSignalOptions{
+				Verbose:  testSignal("verbose"),
+				Quiet:    testSignal("quiet"),
+				MaxLevel: LevelTrace,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newLevelSignals(nil, tc.opts)
+			defer s.stop()
+
+			assert.Equal(t, tc.expectedOpts, s.opts)
+		})
+	}
+}
+
+func TestLevelSignals_Loggers(t *testing.T) {
+	verbose, quiet := testSignal("verbose"), testSignal("quiet")
+
+	for _, tc := range testBackends {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &lockedBuffer{}
+			logger := tc.newLogger(Options{
+				Level:   "warn",
+				Writers: []io.Writer{buf},
+			})
+
+			s := newLevelSignals(logger, LevelSignalOptions{
+				Verbose: verbose,
+				Quiet:   quiet,
+			})
+			defer s.stop()
+
+			send := func(sig testSignal, expected Level) {
+				s.signals <- sig
+				assert.Eventually(t, func() bool {
+					return logger.GetLevel() == expected
+				}, time.Second, time.Millisecond)
+			}
+
+			send(verbose, LevelInfo)
+			send(verbose, LevelDebug)
+			send(verbose, LevelDebug)
+
+			assert.Eventually(t, func() bool {
+				return strings.Count(buf.String(), "logging level changed") == 2
+			}, time.Second, time.Millisecond)
+			assert.Contains(t, buf.String(), `"from":"warn"`)
+			assert.Contains(t, buf.String(), `"to":"info"`)
+			assert.Contains(t, buf.String(), `"signal":"verbose"`)
+
+			send(quiet, LevelInfo)
+			send(quiet, LevelWarn)
+			send(quiet, LevelError)
+			send(quiet, LevelError)
+
+			assert.Eventually(t, func() bool {
+				return strings.Count(buf.String(), "logging level changed") == 5
+			}, time.Second, time.Millisecond)
+			assert.Contains(t, buf.String(), `"to":"error"`)
+
+			assert.NoError(t, logger.Close())
+		})
+
+		t.Run(tc.name+"None", func(t *testing.T) {
+			buf := &lockedBuffer{}
+			logger := tc.newLogger(Options{
+				Level:   "none",
+				Writers: []io.Writer{buf},
+			})
+
+			s := newLevelSignals(logger, LevelSignalOptions{
+				Verbose: verbose,
+				Quiet:   quiet,
+			})
+			defer s.stop()
+
+			// The change from LevelNone is logged in the other level.
+			s.signals <- verbose
+			assert.Eventually(t, func() bool {
+				return strings.Contains(buf.String(), "logging level changed")
+			}, time.Second, time.Millisecond)
+			assert.Contains(t, buf.String(), `"level":"error"`)
+			assert.Contains(t, buf.String(), `"from":"none"`)
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+}
+
+func TestLevelSignals_Singleton(t *testing.T) {
+	defer ReplaceSingleton(nil)()
+
+	verbose := testSignal("verbose")
+	s := newLevelSignals(nil, LevelSignalOptions{Verbose: verbose})
+
+	// No singleton logger is set.
+	s.signals <- verbose
+
+	logger := NewZap(Options{Level: "info", Writers: []io.Writer{&lockedBuffer{}}})
+	SetSingleton(logger)
+
+	s.signals <- verbose
+	assert.Eventually(t, func() bool {
+		return logger.GetLevel() == LevelDebug
+	}, time.Second, time.Millisecond)
+
+	s.stop()
+	s.stop()
+}
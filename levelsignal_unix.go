@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package log
+
+import "syscall"
+
+// Default signals for changing the logging level
+var (
+	defaultVerboseSignal = syscall.SIGUSR1
+	defaultQuietSignal   = syscall.SIGUSR2
+)
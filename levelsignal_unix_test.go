@@ -0,0 +1,31 @@
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleLevelSignals(t *testing.T) {
+	logger := NewKit(Options{Level: "info", Writers: []io.Writer{&lockedBuffer{}}})
+	stop := HandleLevelSignals(logger, LevelSignalOptions{})
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		return logger.GetLevel() == LevelDebug
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assert.Eventually(t, func() bool {
+		return logger.GetLevel() == LevelInfo
+	}, time.Second, time.Millisecond)
+
+	stop()
+	stop()
+}
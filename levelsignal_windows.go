@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package log
+
+import "os"
+
+// There are no user-defined signals on Windows, so the signals for changing the logging level must be configured.
+var (
+	defaultVerboseSignal os.Signal
+	defaultQuietSignal   os.Signal
+)
@@ -0,0 +1,313 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.uber.org/multierr"
+)
+
+// levelSpec is a parsed level spec.
+// A level spec is a comma-separated list of an optional default level and name=level pairs (info,db=debug,http.client=warn).
+type levelSpec struct {
+	defaultLevel Level
+	hasDefault   bool
+	levels       map[string]Level
+}
+
+// parseLevelSpec parses a level spec and returns an error describing all invalid entries.
+func parseLevelSpec(spec string) (levelSpec, error) {
+	var err error
+	s := levelSpec{
+		levels: map[string]Level{},
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		i := strings.Index(entry, "=")
+		if i < 0 {
+			level, e := ParseLevel(entry)
+			switch {
+			case e != nil:
+				err = multierr.Append(err, fmt.Errorf("invalid level spec entry %q: %s", entry, e))
+			case s.hasDefault:
+				err = multierr.Append(err, fmt.Errorf("invalid level spec entry %q: default level already set", entry))
+			default:
+				s.defaultLevel, s.hasDefault = level, true
+			}
+			continue
+		}
+
+		name, value := strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+		level, e := ParseLevel(value)
+		switch {
+		case name == "":
+			err = multierr.Append(err, fmt.Errorf("invalid level spec entry %q: name cannot be empty", entry))
+		case value == "":
+			err = multierr.Append(err, fmt.Errorf("invalid level spec entry %q: level cannot be empty", entry))
+		case e != nil:
+			err = multierr.Append(err, fmt.Errorf("invalid level spec entry %q: %s", entry, e))
+		default:
+			if _, ok := s.levels[name]; ok {
+				err = multierr.Append(err, fmt.Errorf("invalid level spec entry %q: level for %s already set", entry, name))
+			} else {
+				s.levels[name] = level
+			}
+		}
+	}
+
+	return s, err
+}
+
+// optionsLevelSpec returns the level spec of the options.
+// The value of the LevelSpecEnv environment variable takes precedence over LevelSpec if it is not empty.
+func optionsLevelSpec(opts Options) string {
+	if opts.LevelSpecEnv != "" {
+		if spec := os.Getenv(opts.LevelSpecEnv); spec != "" {
+			return spec
+		}
+	}
+	return opts.LevelSpec
+}
+
+// levelStore is a logging level that can be read and changed concurrently by loggers.
+type levelStore interface {
+	Load() Level
+	Store(Level)
+}
+
+// levelRegistry keeps the logging levels of the named loggers created from a root logger.
+// All loggers with the same name share the same level.
+//
+// The level of a name is the level of the most specific rule whose name is the same as or a dot-separated prefix of the name.
+// The rules are set by the level spec and by changing the level of a named logger.
+// The rule with an empty name is the level of the root logger and it matches all names.
+// Names are relative to the name of the root logger.
+type levelRegistry struct {
+	sync.Mutex
+	root     string
+	rules    map[string]Level
+	levels   map[string]levelStore
+	newLevel func(Level) levelStore
+}
+
+// newLevelRegistry creates a registry for the level of a root logger and applies a level spec to it.
+func newLevelRegistry(root string, level levelStore, spec levelSpec, newLevel func(Level) levelStore) *levelRegistry {
+	r := &levelRegistry{
+		root: root,
+		rules: map[string]Level{
+			"": level.Load(),
+		},
+		levels: map[string]levelStore{
+			"": level,
+		},
+		newLevel: newLevel,
+	}
+
+	r.apply(spec)
+
+	return r
+}
+
+// relative returns the name of a logger relative to the name of the root logger.
+func (r *levelRegistry) relative(name string) string {
+	switch {
+	case r.root == "":
+		return name
+	case name == r.root:
+		return ""
+	default:
+		return strings.TrimPrefix(name, r.root+".")
+	}
+}
+
+// matches determines whether or not a rule applies to a name.
+func matches(rule, name string) bool {
+	return rule == "" || name == rule || strings.HasPrefix(name, rule+".")
+}
+
+// resolve returns the most specific rule for a name.
+// The lock must be held.
+func (r *levelRegistry) resolve(name string) string {
+	best := ""
+	for rule := range r.rules {
+		if len(rule) > len(best) && matches(rule, name) {
+			best = rule
+		}
+	}
+
+	return best
+}
+
+// level returns the level shared by the loggers with a given name.
+func (r *levelRegistry) level(name string) levelStore {
+	r.Lock()
+	defer r.Unlock()
+
+	name = r.relative(name)
+	if l, ok := r.levels[name]; ok {
+		return l
+	}
+
+	l := r.newLevel(r.rules[r.resolve(name)])
+	r.levels[name] = l
+
+	return l
+}
+
+// set changes the level of a name and the names under it that do not have a more specific rule.
+func (r *levelRegistry) set(name string, level Level) {
+	r.Lock()
+	defer r.Unlock()
+
+	name = r.relative(name)
+	r.rules[name] = level
+
+	for n, l := range r.levels {
+		if r.resolve(n) == name {
+			l.Store(level)
+		}
+	}
+}
+
+// apply replaces the rules with a level spec and updates the levels of all names.
+// If the spec does not have a default level, the level of the root logger does not change.
+func (r *levelRegistry) apply(spec levelSpec) {
+	r.Lock()
+	defer r.Unlock()
+
+	root := r.rules[""]
+	if spec.hasDefault {
+		root = spec.defaultLevel
+	}
+
+	r.rules = map[string]Level{
+		"": root,
+	}
+
+	for name, level := range spec.levels {
+		r.rules[name] = level
+	}
+
+	for n, l := range r.levels {
+		l.Store(r.rules[r.resolve(n)])
+	}
+}
+
+// unset removes the rule of a name, so the name and the names under it that do not have a more specific rule
+// use the level of the next matching rule. The rule of the root logger is never removed.
+func (r *levelRegistry) unset(name string) {
+	r.Lock()
+	defer r.Unlock()
+
+	name = r.relative(name)
+	if name == "" {
+		return
+	}
+
+	delete(r.rules, name)
+
+	for n, l := range r.levels {
+		l.Store(r.rules[r.resolve(n)])
+	}
+}
+
+// hasRule determines whether or not a name has its own rule.
+// The root logger always has a rule.
+func (r *levelRegistry) hasRule(name string) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	_, ok := r.rules[r.relative(name)]
+	return ok
+}
+
+// has determines whether or not a name, other than the root logger, has a logger or a rule.
+func (r *levelRegistry) has(name string) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	name = r.relative(name)
+	if name == "" {
+		return false
+	}
+
+	_, ok := r.levels[name]
+	if !ok {
+		_, ok = r.rules[name]
+	}
+
+	return ok
+}
+
+// list returns the levels of all names except the root logger.
+func (r *levelRegistry) list() map[string]Level {
+	r.Lock()
+	defer r.Unlock()
+
+	levels := make(map[string]Level, len(r.levels))
+	for n, l := range r.levels {
+		if n != "" {
+			levels[n] = l.Load()
+		}
+	}
+
+	return levels
+}
+
+// levelSpecSetter is implemented by loggers that support level specs.
+type levelSpecSetter interface {
+	setLevelSpec(spec levelSpec)
+}
+
+// namedLeveler is implemented by loggers that keep the levels of their named loggers.
+type namedLeveler interface {
+	namedLevels() map[string]Level
+	hasNamedLevel(name string) bool
+}
+
+// levelSaver is implemented by loggers that can restore their logging levels,
+// including named loggers going back to not having their own levels.
+type levelSaver interface {
+	saveLevel() func()
+}
+
+// saveLevel returns a function that changes the logging level of a logger back to its current level.
+// A named logger without its own level goes back to using the level of the logger it is named from.
+func saveLevel(l Logger) func() {
+	if ls, ok := l.(levelSaver); ok {
+		return ls.saveLevel()
+	}
+
+	level := l.GetLevel()
+	return func() {
+		_ = l.SetLevelTo(level)
+	}
+}
+
+// SetLevelSpec changes the logging levels of a logger and all loggers created from the same root logger using a level spec.
+// A level spec is a comma-separated list of an optional default level and name=level pairs (info,db=debug,http.client=warn).
+// The names are the names of the loggers created using Named without the name of the root logger (Options.Name).
+// A named logger uses the level of the most specific name that is the same as or a dot-separated prefix of its name.
+// The default level is the level of the root logger and the loggers whose names do not match any name in the spec.
+//
+// Existing loggers are changed without being recreated.
+// Loggers created using WithLevel and their children are not changed.
+func SetLevelSpec(l Logger, spec string) error {
+	s, err := parseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	if ls, ok := l.(levelSpecSetter); ok {
+		ls.setLevelSpec(s)
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevelSpec(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		expectedSpec  levelSpec
+		expectedError string
+	}{
+		{
+			name:         "Empty",
+			spec:         "",
+			expectedSpec: levelSpec{levels: map[string]Level{}},
+		},
+		{
+			name:         "DefaultOnly",
+			spec:         "warn",
+			expectedSpec: levelSpec{defaultLevel: LevelWarn, hasDefault: true, levels: map[string]Level{}},
+		},
+		{
+			name: "NamesOnly",
+			spec: "db=debug,http.client=none",
+			expectedSpec: levelSpec{
+				levels: map[string]Level{"db": LevelDebug, "http.client": LevelNone},
+			},
+		},
+		{
+			name: "Full",
+			spec: " info , db = Debug ,, http.client=warn ",
+			expectedSpec: levelSpec{
+				defaultLevel: LevelInfo,
+				hasDefault:   true,
+				levels:       map[string]Level{"db": LevelDebug, "http.client": LevelWarn},
+			},
+		},
+		{
+			name:          "InvalidDefault",
+			spec:          "verbose,db=debug",
+			expectedSpec:  levelSpec{levels: map[string]Level{"db": LevelDebug}},
+			expectedError: `invalid level spec entry "verbose": invalid level "verbose": must be one of trace, debug, info, warn, error, or none`,
+		},
+		{
+			name:          "DuplicateDefault",
+			spec:          "info,warn",
+			expectedSpec:  levelSpec{defaultLevel: LevelInfo, hasDefault: true, levels: map[string]Level{}},
+			expectedError: `invalid level spec entry "warn": default level already set`,
+		},
+		{
+			name:          "InvalidEntries",
+			spec:          "=debug,db=,http=verbose,db=info,db=warn",
+			expectedSpec:  levelSpec{levels: map[string]Level{"db": LevelInfo}},
+			expectedError: `invalid level spec entry "=debug": name cannot be empty; invalid level spec entry "db=": level cannot be empty; invalid level spec entry "http=verbose": invalid level "verbose": must be one of trace, debug, info, warn, error, or none; invalid level spec entry "db=warn": level for db already set`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := parseLevelSpec(tc.spec)
+
+			assert.Equal(t, tc.expectedSpec, spec)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOptionsLevelSpec(t *testing.T) {
+	const env = "LOG_LEVEL_SPEC_TEST"
+
+	tests := []struct {
+		name         string
+		envValue     string
+		opts         Options
+		expectedSpec string
+	}{
+		{
+			name:         "NoSpec",
+			opts:         Options{},
+			expectedSpec: "",
+		},
+		{
+			name:         "LevelSpec",
+			opts:         Options{LevelSpec: "info,db=debug", LevelSpecEnv: env},
+			expectedSpec: "info,db=debug",
+		},
+		{
+			name:         "LevelSpecEnv",
+			envValue:     "warn,http=debug",
+			opts:         Options{LevelSpec: "info,db=debug", LevelSpecEnv: env},
+			expectedSpec: "warn,http=debug",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.envValue != "" {
+				assert.NoError(t, os.Setenv(env, tc.envValue))
+				defer os.Unsetenv(env)
+			}
+
+			assert.Equal(t, tc.expectedSpec, optionsLevelSpec(tc.opts))
+		})
+	}
+}
+
+func TestLevelRegistry(t *testing.T) {
+	newLevel := func(l Level) levelStore {
+		return newAtomicLevel(l)
+	}
+
+	spec, err := parseLevelSpec("db=debug,http.client=warn")
+	assert.NoError(t, err)
+
+	root := newAtomicLevel(LevelInfo)
+	r := newLevelRegistry("my-service", root, spec, newLevel)
+
+	assert.Equal(t, root, r.level("my-service"))
+	assert.Equal(t, LevelDebug, r.level("my-service.db").Load())
+	assert.Equal(t, LevelDebug, r.level("my-service.db.pool").Load())
+	assert.Equal(t, LevelInfo, r.level("my-service.http").Load())
+	assert.Equal(t, LevelWarn, r.level("my-service.http.client").Load())
+	assert.Equal(t, LevelInfo, r.level("my-service.dbx").Load())
+
+	// Loggers with the same name share the same level.
+	assert.Equal(t, r.level("my-service.db"), r.level("my-service.db"))
+
+	// Changing a level changes the names under it without more specific rules.
+	r.set("my-service", LevelError)
+	assert.Equal(t, LevelError, root.Load())
+	assert.Equal(t, LevelError, r.level("my-service.http").Load())
+	assert.Equal(t, LevelError, r.level("my-service.dbx").Load())
+	assert.Equal(t, LevelDebug, r.level("my-service.db").Load())
+	assert.Equal(t, LevelWarn, r.level("my-service.http.client").Load())
+
+	r.set("my-service.http", LevelTrace)
+	assert.Equal(t, LevelTrace, r.level("my-service.http").Load())
+	assert.Equal(t, LevelTrace, r.level("my-service.http.server").Load())
+	assert.Equal(t, LevelWarn, r.level("my-service.http.client").Load())
+
+	// Removing a rule changes the names under it to the level of the next matching rule.
+	assert.True(t, r.hasRule("my-service.http"))
+	r.unset("my-service.http")
+	assert.False(t, r.hasRule("my-service.http"))
+	assert.Equal(t, LevelError, r.level("my-service.http").Load())
+	assert.Equal(t, LevelError, r.level("my-service.http.server").Load())
+	assert.Equal(t, LevelWarn, r.level("my-service.http.client").Load())
+
+	// The rule of the root logger is never removed.
+	r.unset("my-service")
+	assert.True(t, r.hasRule("my-service"))
+	assert.Equal(t, LevelError, root.Load())
+
+	// Applying a spec without a default level keeps the root level.
+	spec, err = parseLevelSpec("http=debug")
+	assert.NoError(t, err)
+
+	r.apply(spec)
+	assert.Equal(t, LevelError, root.Load())
+	assert.Equal(t, LevelError, r.level("my-service.db").Load())
+	assert.Equal(t, LevelError, r.level("my-service.db.pool").Load())
+	assert.Equal(t, LevelDebug, r.level("my-service.http").Load())
+	assert.Equal(t, LevelDebug, r.level("my-service.http.client").Load())
+
+	spec, err = parseLevelSpec("none")
+	assert.NoError(t, err)
+
+	r.apply(spec)
+	assert.Equal(t, LevelNone, root.Load())
+	assert.Equal(t, LevelNone, r.level("my-service.http.client").Load())
+}
+
+func TestSetLevelSpec(t *testing.T) {
+	tests := []struct {
+		name          string
+		logger        Logger
+		spec          string
+		expectedError string
+	}{
+		{
+			name:   "NopLogger",
+			logger: NewNopLogger(),
+			spec:   "info,db=debug",
+		},
+		{
+			name:   "MockLogger",
+			logger: &mockLogger{},
+			spec:   "info,db=debug",
+		},
+		{
+			name:          "InvalidSpec",
+			logger:        NewKit(Options{}),
+			spec:          "db=verbose",
+			expectedError: `invalid level spec entry "db=verbose": invalid level "verbose": must be one of trace, debug, info, warn, error, or none`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := SetLevelSpec(tc.logger, tc.spec)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLevelSpec_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			root := tc.newLogger(Options{
+				Name:      "my-service",
+				Level:     "error",
+				LevelSpec: "info,db=debug,http.client=warn",
+				Writers:   []io.Writer{buf},
+			})
+
+			db := root.Named("db")
+			pool := db.With("pool", "primary").Named("pool")
+			http := root.Named("http")
+			client := http.Named("client")
+
+			assert.Equal(t, LevelInfo, root.GetLevel())
+			assert.Equal(t, LevelDebug, db.GetLevel())
+			assert.Equal(t, LevelDebug, pool.GetLevel())
+			assert.Equal(t, LevelInfo, http.GetLevel())
+			assert.Equal(t, LevelWarn, client.GetLevel())
+
+			pool.Debug("pool debug")
+			client.Info("client info")
+			assert.Contains(t, buf.String(), "pool debug")
+			assert.NotContains(t, buf.String(), "client info")
+
+			// Changing the spec retargets the existing loggers.
+			assert.NoError(t, SetLevelSpec(root, "warn,http=debug"))
+			assert.Equal(t, LevelWarn, root.GetLevel())
+			assert.Equal(t, LevelWarn, db.GetLevel())
+			assert.Equal(t, LevelWarn, pool.GetLevel())
+			assert.Equal(t, LevelDebug, http.GetLevel())
+			assert.Equal(t, LevelDebug, client.GetLevel())
+
+			client.Debug("client debug")
+			pool.Info("pool info")
+			assert.Contains(t, buf.String(), "client debug")
+			assert.NotContains(t, buf.String(), "pool info")
+
+			// Changing the level of a named logger changes the named loggers under it.
+			http.SetLevel("error")
+			assert.Equal(t, LevelError, client.GetLevel())
+			assert.Equal(t, LevelError, root.Named("http").Named("server").GetLevel())
+			assert.Equal(t, LevelWarn, root.GetLevel())
+
+			// Named loggers created from a logger with its own level share its level.
+			detached := db.WithLevel(LevelTrace).Named("cache")
+			assert.Equal(t, LevelTrace, detached.GetLevel())
+			assert.NoError(t, SetLevelSpec(root, "none"))
+			assert.Equal(t, LevelTrace, detached.GetLevel())
+			assert.Equal(t, LevelNone, db.GetLevel())
+
+			assert.NoError(t, root.Close())
+		})
+	}
+}
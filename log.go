@@ -6,7 +6,16 @@
 // The instance logger can be further used to create more contextualized loggers as the children of the root logger.
 package log
 
-import "strings"
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+)
 
 // Format is the logging format.
 type Format int
@@ -17,6 +26,58 @@ const (
 	FormatConsole
 )
 
+// ParseFormat returns the logging format for a format name.
+// A format name can be "json" or "console" (case-insensitive) and an empty name is the default format (json).
+func ParseFormat(format string) (Format, error) {
+	switch strings.ToLower(format) {
+	case "", "json":
+		return FormatJSON, nil
+	case "console":
+		return FormatConsole, nil
+	default:
+		return FormatJSON, fmt.Errorf("invalid format %q: must be one of json or console", format)
+	}
+}
+
+// String returns the name of the logging format.
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatConsole:
+		return "console"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// It returns an error for an invalid logging format.
+func (f Format) MarshalText() ([]byte, error) {
+	if f != FormatJSON && f != FormatConsole {
+		return nil, fmt.Errorf("invalid format %d: must be FormatJSON or FormatConsole", f)
+	}
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// It accepts the same format names as ParseFormat.
+func (f *Format) UnmarshalText(text []byte) error {
+	format, err := ParseFormat(string(text))
+	if err != nil {
+		return err
+	}
+
+	*f = format
+	return nil
+}
+
+// Set implements flag.Value.
+// It accepts the same format names as ParseFormat.
+func (f *Format) Set(format string) error {
+	return f.UnmarshalText([]byte(format))
+}
+
 // Level is the logging level.
 type Level int
 
@@ -27,45 +88,262 @@ const (
 	LevelWarn
 	LevelInfo
 	LevelDebug
+	LevelTrace
 )
 
-func parseLevel(level string) Level {
+// ParseLevel returns the logging level for a level name.
+// A level name can be "trace", "debug", "info", "warn", "error", or "none" (case-insensitive)
+// and an empty name is the default level (info).
+func ParseLevel(level string) (Level, error) {
+	l, ok := lookupLevel(level)
+	if !ok {
+		return LevelNone, fmt.Errorf("invalid level %q: must be one of trace, debug, info, warn, error, or none", level)
+	}
+	return l, nil
+}
+
+// String returns the name of the logging level.
+func (l Level) String() string {
+	switch l {
+	case LevelNone:
+		return "none"
+	case LevelError:
+		return "error"
+	case LevelWarn:
+		return "warn"
+	case LevelInfo:
+		return "info"
+	case LevelDebug:
+		return "debug"
+	case LevelTrace:
+		return "trace"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// validate returns an error if the logging level is not one of the defined levels.
+func (l Level) validate() error {
+	if l < LevelNone || l > LevelTrace {
+		return fmt.Errorf("invalid level %d: must be between LevelNone and LevelTrace", l)
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// It returns an error for an invalid logging level.
+func (l Level) MarshalText() ([]byte, error) {
+	if err := l.validate(); err != nil {
+		return nil, err
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// It accepts the same level names as ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = level
+	return nil
+}
+
+// Set implements flag.Value.
+// It accepts the same level names as ParseLevel.
+func (l *Level) Set(level string) error {
+	return l.UnmarshalText([]byte(level))
+}
+
+// atomicLevel is a logging level that can be read and changed concurrently.
+type atomicLevel struct {
+	v int32
+}
+
+func newAtomicLevel(l Level) *atomicLevel {
+	return &atomicLevel{
+		v: int32(l),
+	}
+}
+
+// Load returns the current logging level.
+func (a *atomicLevel) Load() Level {
+	return Level(atomic.LoadInt32(&a.v))
+}
+
+// Store changes the logging level.
+func (a *atomicLevel) Store(l Level) {
+	atomic.StoreInt32(&a.v, int32(l))
+}
+
+// lookupLevel returns the logging level for a level name.
+// An empty name is the default level (info).
+func lookupLevel(level string) (Level, bool) {
 	switch strings.ToLower(level) {
+	case "trace":
+		return LevelTrace, true
 	case "debug":
-		return LevelDebug
+		return LevelDebug, true
 	case "": // default
 		fallthrough
 	case "info":
-		return LevelInfo
+		return LevelInfo, true
 	case "warn":
-		return LevelWarn
+		return LevelWarn, true
 	case "error":
-		return LevelError
+		return LevelError, true
 	case "none":
-		fallthrough
+		return LevelNone, true
 	default:
-		return LevelNone
+		return LevelNone, false
 	}
 }
 
+// parseLevel returns the logging level for a level name.
+// Unknown level names are parsed as LevelNone.
+func parseLevel(level string) Level {
+	l, _ := lookupLevel(level)
+	return l
+}
+
 // Options are optional configurations for creating a logger.
-// Level can be "debug", "info", "warn", "error", or "none" (case-insensitive).
+// Level can be "trace", "debug", "info", "warn", "error", or "none" (case-insensitive).
+//
+// LevelSpec configures the levels of named loggers (see SetLevelSpec), e.g. info,db=debug,http.client=warn.
+// If LevelSpecEnv is set, the level spec is read from the environment variable named LevelSpecEnv and LevelSpec is used only if the variable is empty.
+// The default level of the level spec takes precedence over Level.
+//
+// OutputPaths and ErrorOutputPaths can be "stdout", "stderr", file URLs (file:///var/log/app.log), or file paths.
+// Logs are written to all OutputPaths and Writers. If none of them is set, logs are written to stdout.
+// Internal errors of a logger (e.g. failed writes) are written to all ErrorOutputPaths and ErrorWriters.
+// If none of them is set, internal errors are written to stderr.
+// If Rotate is set, logs are also written to a rotating file (see RotateOptions).
+//
+// Routes can be used for writing logs with different levels to different outputs (see Route).
+// Logs not matching any route are written to the outputs above.
+//
+// If BufferSize is set, writes to the outputs are buffered up to BufferSize bytes.
+// Buffered logs are written when the buffer is full, every FlushInterval (one second by default), and when the logger is closed.
+//
+// If Async is set, logs are written to the outputs asynchronously (see AsyncOptions).
+//
+// If FlightRecorder is set, the last FlightRecorder log entries of all levels are recorded in memory by the process-wide flight recorder,
+// including the entries not logged because of the logging level (see DumpFlightRecorder).
+// The recorded entries are written to the error outputs after a fatal log.
+//
+// ExitFunc is called for exiting the process after a fatal log and it is os.Exit by default.
+// It can be replaced for testing fatal logs.
 type Options struct {
-	Name        string
-	Version     string
-	Environment string
-	Region      string
-	Tags        map[string]string
-	Level       string
-	Format      Format
+	Name             string
+	Version          string
+	Environment      string
+	Region           string
+	Tags             map[string]string
+	Level            string
+	LevelSpec        string
+	LevelSpecEnv     string
+	Format           Format
+	OutputPaths      []string
+	Writers          []io.Writer
+	ErrorOutputPaths []string
+	ErrorWriters     []io.Writer
+	Rotate           *RotateOptions
+	Routes           []Route
+	BufferSize       int
+	FlushInterval    time.Duration
+	Async            *AsyncOptions
+	FlightRecorder   int
+	ExitFunc         func(int)
+}
+
+// Validate checks the options and returns an error describing all invalid options.
+// Output paths are checked without being opened.
+func (opts Options) Validate() error {
+	var err error
+
+	if _, e := ParseLevel(opts.Level); e != nil {
+		err = multierr.Append(err, e)
+	}
+
+	if _, e := parseLevelSpec(optionsLevelSpec(opts)); e != nil {
+		err = multierr.Append(err, e)
+	}
+
+	if opts.Format != FormatJSON && opts.Format != FormatConsole {
+		err = multierr.Append(err, fmt.Errorf("invalid format %d: must be FormatJSON or FormatConsole", opts.Format))
+	}
+
+	err = multierr.Append(err, validateSinks(opts.OutputPaths, opts.Writers))
+	err = multierr.Append(err, validateSinks(opts.ErrorOutputPaths, opts.ErrorWriters))
+
+	if opts.Rotate != nil {
+		err = multierr.Append(err, opts.Rotate.validate())
+	}
+
+	for i, r := range opts.Routes {
+		if e := r.validate(); e != nil {
+			err = multierr.Append(err, fmt.Errorf("invalid route %d: %s", i, e))
+		}
+	}
+
+	if opts.BufferSize < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid buffer size %d: cannot be negative", opts.BufferSize))
+	}
+
+	if opts.FlushInterval < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid flush interval %s: cannot be negative", opts.FlushInterval))
+	}
+
+	if opts.Async != nil {
+		err = multierr.Append(err, opts.Async.validate())
+	}
+
+	if opts.FlightRecorder < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid flight recorder size %d: cannot be negative", opts.FlightRecorder))
+	}
+
+	return err
 }
 
 // Logger is a leveled structured logger.
 // It is concurrently safe to be used by multiple goroutines.
+//
+// A logger created using With shares the logging level with its parent.
+// Changing the level of any of them using SetLevel or SetLevelTo changes the level of all of them.
+// WithLevel creates a logger with its own logging level that is independent of its parent.
+// Invalid levels are treated as LevelNone by WithLevel.
+//
+// SetLevelFor changes the logging level for a duration and then changes it back automatically.
+// The returned cancel function changes the level back before the duration ends.
+// Overlapping changes of the same level are stacked, so the level is changed back to the level before the first change
+// only when all of them have ended. Level changes made using SetLevel or SetLevelTo in the meantime are overwritten.
+// A named logger that did not have its own level before the first change goes back to using the level of its parent.
+// The changes and the reverts are logged.
+//
+// Named creates a logger whose name is the name of its parent followed by a dot and the given name (my-service.http.client).
+// The name is logged with the logger key and the root name is Options.Name.
+//
+// Log and Logf log in a given level, so they can be used by helpers wrapping a logger.
+// Enabled determines whether or not a level is logged, so expensive key-value pairs can be computed only when needed.
+// Logs in LevelNone or an invalid level are never logged.
+//
+// Fatal logs a message, flushes the logger, and exits the process. Panic logs a message, flushes the logger, and panics.
+// Fatal and panic logs are logged in any level except LevelNone and they are routed like error logs.
 type Logger interface {
 	With(kv ...interface{}) Logger
+	WithLevel(level Level) Logger
 	GetLevel() Level
+	Named(name string) Logger
 	SetLevel(level string)
+	SetLevelTo(level Level) error
+	SetLevelFor(level Level, d time.Duration) (cancel func(), err error)
+	Enabled(level Level) bool
+	Log(level Level, message string, kv ...interface{})
+	Logf(level Level, format string, args ...interface{})
+	Trace(message string, kv ...interface{})
+	Tracef(format string, args ...interface{})
 	Debug(message string, kv ...interface{})
 	Debugf(format string, args ...interface{})
 	Info(message string, kv ...interface{})
@@ -74,26 +352,89 @@ type Logger interface {
 	Warnf(format string, args ...interface{})
 	Error(message string, kv ...interface{})
 	Errorf(format string, args ...interface{})
+	Fatal(message string, kv ...interface{})
+	Fatalf(format string, args ...interface{})
+	Panic(message string, kv ...interface{})
+	Panicf(format string, args ...interface{})
 	Close() error
 }
 
+// callerSkipper is implemented by loggers that report the callers of their logging methods.
+// Loggers wrapping other loggers use it for skipping their own stack frames.
+type callerSkipper interface {
+	addCallerSkip(skip int) Logger
+}
+
+// addCallerSkip returns a copy of a logger that skips extra stack frames for reporting the caller.
+// If the logger does not report the caller, the logger itself is returned.
+func addCallerSkip(l Logger, skip int) Logger {
+	if cs, ok := l.(callerSkipper); ok {
+		return cs.addCallerSkip(skip)
+	}
+	return l
+}
+
+// joinName joins the name of a parent logger and a child name with a dot.
+func joinName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+	return parent + "." + name
+}
+
+// exiter is implemented by loggers that exit the process after fatal logs.
+// Loggers wrapping other loggers use it for exiting the process once.
+type exiter interface {
+	exitFunc() func(int)
+	withExitFunc(exit func(int)) Logger
+}
+
+// exitFunc returns the exit function if it is set and os.Exit otherwise.
+func exitFunc(exit func(int)) func(int) {
+	if exit != nil {
+		return exit
+	}
+	return os.Exit
+}
+
 type nopLogger struct{}
 
 // NewNopLogger creates a logger that never logs anything to anywhere!
 // It can be used for testing purposes.
+// Unlike every other logger, its Fatal methods deliberately do not exit the process,
+// so a tee of nop loggers does not exit the process either; its Panic methods still panic.
 func NewNopLogger() Logger {
 	return &nopLogger{}
 }
 
-func (l *nopLogger) With(kv ...interface{}) Logger             { return l }
-func (l *nopLogger) GetLevel() Level                           { return LevelNone }
-func (l *nopLogger) SetLevel(level string)                     {}
-func (l *nopLogger) Debug(message string, kv ...interface{})   {}
-func (l *nopLogger) Debugf(format string, args ...interface{}) {}
-func (l *nopLogger) Info(message string, kv ...interface{})    {}
-func (l *nopLogger) Infof(format string, args ...interface{})  {}
-func (l *nopLogger) Warn(message string, kv ...interface{})    {}
-func (l *nopLogger) Warnf(format string, args ...interface{})  {}
-func (l *nopLogger) Error(message string, kv ...interface{})   {}
-func (l *nopLogger) Errorf(format string, args ...interface{}) {}
-func (l *nopLogger) Close() error                              { return nil }
+func (l *nopLogger) With(kv ...interface{}) Logger                        { return l }
+func (l *nopLogger) WithLevel(level Level) Logger                         { return l }
+func (l *nopLogger) Named(name string) Logger                             { return l }
+func (l *nopLogger) GetLevel() Level                                      { return LevelNone }
+func (l *nopLogger) SetLevel(level string)                                {}
+func (l *nopLogger) SetLevelTo(level Level) error                         { return level.validate() }
+func (l *nopLogger) Enabled(level Level) bool                             { return false }
+func (l *nopLogger) Log(level Level, message string, kv ...interface{})   {}
+func (l *nopLogger) Logf(level Level, format string, args ...interface{}) {}
+func (l *nopLogger) Trace(message string, kv ...interface{})              {}
+func (l *nopLogger) Tracef(format string, args ...interface{})            {}
+func (l *nopLogger) Debug(message string, kv ...interface{})              {}
+func (l *nopLogger) Debugf(format string, args ...interface{})            {}
+func (l *nopLogger) Info(message string, kv ...interface{})               {}
+func (l *nopLogger) Infof(format string, args ...interface{})             {}
+func (l *nopLogger) Warn(message string, kv ...interface{})               {}
+func (l *nopLogger) Warnf(format string, args ...interface{})             {}
+func (l *nopLogger) Error(message string, kv ...interface{})              {}
+func (l *nopLogger) Errorf(format string, args ...interface{})            {}
+func (l *nopLogger) Fatal(message string, kv ...interface{})              {}
+func (l *nopLogger) Fatalf(format string, args ...interface{})            {}
+func (l *nopLogger) Panic(message string, kv ...interface{})              { panic(message) }
+func (l *nopLogger) Panicf(format string, args ...interface{})            { panic(fmt.Sprintf(format, args...)) }
+func (l *nopLogger) Close() error                                         { return nil }
+
+func (l *nopLogger) SetLevelFor(level Level, d time.Duration) (func(), error) {
+	return func() {}, validateLevelFor(level, d)
+}
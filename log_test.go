@@ -1,34 +1,72 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 // mockLogger is a mock implementation of Logger
 type mockLogger struct {
-	WithInKV         []interface{}
-	WithOutLogger    Logger
-	GetLevelOutLevel Level
-	SetLevelInLevel  string
-	DebugInMessage   string
-	DebugInKV        []interface{}
-	DebugfInFormat   string
-	DebugfInArgs     []interface{}
-	InfoInMessage    string
-	InfoInKV         []interface{}
-	InfofInFormat    string
-	InfofInArgs      []interface{}
-	WarnInMessage    string
-	WarnInKV         []interface{}
-	WarnfInFormat    string
-	WarnfInArgs      []interface{}
-	ErrorInMessage   string
-	ErrorInKV        []interface{}
-	ErrorfInFormat   string
-	ErrorfInArgs     []interface{}
-	CloseOutError    error
+	WithInKV              []interface{}
+	WithOutLogger         Logger
+	WithLevelInLevel      Level
+	WithLevelOutLogger    Logger
+	NamedInName           string
+	NamedOutLogger        Logger
+	GetLevelOutLevel      Level
+	SetLevelInLevel       string
+	SetLevelToInLevel     Level
+	SetLevelToOutError    error
+	SetLevelForInLevel    Level
+	SetLevelForInDuration time.Duration
+	SetLevelForOutError   error
+	EnabledInLevel        Level
+	EnabledOutResult      bool
+	LogInLevel            Level
+	LogInMessage          string
+	LogInKV               []interface{}
+	LogfInLevel           Level
+	LogfInFormat          string
+	LogfInArgs            []interface{}
+	TraceInMessage        string
+	TraceInKV             []interface{}
+	TracefInFormat        string
+	TracefInArgs          []interface{}
+	DebugInMessage        string
+	DebugInKV             []interface{}
+	DebugfInFormat        string
+	DebugfInArgs          []interface{}
+	InfoInMessage         string
+	InfoInKV              []interface{}
+	InfofInFormat         string
+	InfofInArgs           []interface{}
+	WarnInMessage         string
+	WarnInKV              []interface{}
+	WarnfInFormat         string
+	WarnfInArgs           []interface{}
+	ErrorInMessage        string
+	ErrorInKV             []interface{}
+	ErrorfInFormat        string
+	ErrorfInArgs          []interface{}
+	FatalInMessage        string
+	FatalInKV             []interface{}
+	FatalfInFormat        string
+	FatalfInArgs          []interface{}
+	PanicInMessage        string
+	PanicInKV             []interface{}
+	PanicfInFormat        string
+	PanicfInArgs          []interface{}
+	CloseOutError         error
 }
 
 func (m *mockLogger) With(kv ...interface{}) Logger {
@@ -36,6 +74,16 @@ func (m *mockLogger) With(kv ...interface{}) Logger {
 	return m.WithOutLogger
 }
 
+func (m *mockLogger) WithLevel(level Level) Logger {
+	m.WithLevelInLevel = level
+	return m.WithLevelOutLogger
+}
+
+func (m *mockLogger) Named(name string) Logger {
+	m.NamedInName = name
+	return m.NamedOutLogger
+}
+
 func (m *mockLogger) GetLevel() Level {
 	return m.GetLevelOutLevel
 }
@@ -44,6 +92,38 @@ func (m *mockLogger) SetLevel(level string) {
 	m.SetLevelInLevel = level
 }
 
+func (m *mockLogger) SetLevelTo(level Level) error {
+	m.SetLevelToInLevel = level
+	return m.SetLevelToOutError
+}
+
+func (m *mockLogger) SetLevelFor(level Level, d time.Duration) (func(), error) {
+	m.SetLevelForInLevel = level
+	m.SetLevelForInDuration = d
+	return func() {}, m.SetLevelForOutError
+}
+
+func (m *mockLogger) Enabled(level Level) bool {
+	m.EnabledInLevel = level
+	return m.EnabledOutResult
+}
+
+func (m *mockLogger) Log(level Level, message string, kv ...interface{}) {
+	m.LogInLevel, m.LogInMessage, m.LogInKV = level, message, kv
+}
+
+func (m *mockLogger) Logf(level Level, format string, args ...interface{}) {
+	m.LogfInLevel, m.LogfInFormat, m.LogfInArgs = level, format, args
+}
+
+func (m *mockLogger) Trace(message string, kv ...interface{}) {
+	m.TraceInMessage, m.TraceInKV = message, kv
+}
+
+func (m *mockLogger) Tracef(format string, args ...interface{}) {
+	m.TracefInFormat, m.TracefInArgs = format, args
+}
+
 func (m *mockLogger) Debug(message string, kv ...interface{}) {
 	m.DebugInMessage, m.DebugInKV = message, kv
 }
@@ -76,30 +156,353 @@ func (m *mockLogger) Errorf(format string, args ...interface{}) {
 	m.ErrorfInFormat, m.ErrorfInArgs = format, args
 }
 
+func (m *mockLogger) Fatal(message string, kv ...interface{}) {
+	m.FatalInMessage, m.FatalInKV = message, kv
+}
+
+func (m *mockLogger) Fatalf(format string, args ...interface{}) {
+	m.FatalfInFormat, m.FatalfInArgs = format, args
+}
+
+func (m *mockLogger) Panic(message string, kv ...interface{}) {
+	m.PanicInMessage, m.PanicInKV = message, kv
+	panic(message)
+}
+
+func (m *mockLogger) Panicf(format string, args ...interface{}) {
+	m.PanicfInFormat, m.PanicfInArgs = format, args
+	panic(fmt.Sprintf(format, args...))
+}
+
 func (m *mockLogger) Close() error {
 	return m.CloseOutError
 }
 
+// testLogger creates a logger for running the same test against different loggers.
+type testLogger struct {
+	name      string
+	newLogger func(Options) Logger
+}
+
+// testBackends are the loggers created by the logger backends.
+var testBackends = []testLogger{
+	{"Kit", NewKit},
+	{"Zap", NewZap},
+}
+
+// testLoggers are the loggers created by the logger backends and a tee logger of both backends.
+var testLoggers = append(testBackends[:len(testBackends):len(testBackends)], testLogger{
+	"Tee", func(opts Options) Logger {
+		return NewTee(NewKit(opts), NewZap(opts))
+	},
+})
+
+func TestAtomicLevel(t *testing.T) {
+	level := newAtomicLevel(LevelInfo)
+	assert.Equal(t, LevelInfo, level.Load())
+
+	level.Store(LevelDebug)
+	assert.Equal(t, LevelDebug, level.Load())
+
+	var zero atomicLevel
+	assert.Equal(t, LevelNone, zero.Load())
+}
+
+func TestLookupLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		level         string
+		expectedLevel Level
+		expectedOK    bool
+	}{
+		{"Empty", "", LevelInfo, true},
+		{"None", "none", LevelNone, true},
+		{"Error", "error", LevelError, true},
+		{"Warn", "WARN", LevelWarn, true},
+		{"Info", "Info", LevelInfo, true},
+		{"Debug", "debug", LevelDebug, true},
+		{"Trace", "Trace", LevelTrace, true},
+		{"Warning", "warning", LevelNone, false},
+		{"Typo", "dbg", LevelNone, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			level, ok := lookupLevel(tc.level)
+
+			assert.Equal(t, tc.expectedLevel, level)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
 func TestParseLevel(t *testing.T) {
 	tests := []struct {
 		name          string
 		level         string
 		expectedLevel Level
+		expectedError string
 	}{
-		{"Empty", "", LevelInfo},
-		{"None", "none", LevelNone},
-		{"Error", "error", LevelError},
-		{"Warn", "warn", LevelWarn},
-		{"Info", "info", LevelInfo},
-		{"Debug", "debug", LevelDebug},
-		{"Invalid", "invalid", LevelNone},
+		{"Empty", "", LevelInfo, ""},
+		{"None", "none", LevelNone, ""},
+		{"Error", "error", LevelError, ""},
+		{"Warn", "warn", LevelWarn, ""},
+		{"Info", "info", LevelInfo, ""},
+		{"Debug", "debug", LevelDebug, ""},
+		{"Trace", "trace", LevelTrace, ""},
+		{"Invalid", "invalid", LevelNone, `invalid level "invalid": must be one of trace, debug, info, warn, error, or none`},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			level := parseLevel(tc.level)
+			assert.Equal(t, tc.expectedLevel, level)
 
+			level, err := ParseLevel(tc.level)
 			assert.Equal(t, tc.expectedLevel, level)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	tests := []struct {
+		name           string
+		level          Level
+		expectedString string
+		expectedError  string
+	}{
+		{"None", LevelNone, "none", ""},
+		{"Error", LevelError, "error", ""},
+		{"Warn", LevelWarn, "warn", ""},
+		{"Info", LevelInfo, "info", ""},
+		{"Debug", LevelDebug, "debug", ""},
+		{"Trace", LevelTrace, "trace", ""},
+		{"Invalid", Level(9), "Level(9)", "invalid level 9: must be between LevelNone and LevelTrace"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedString, tc.level.String())
+
+			text, err := tc.level.MarshalText()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedString, string(text))
+
+			var level Level
+			assert.NoError(t, level.UnmarshalText(text))
+			assert.Equal(t, tc.level, level)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		format         string
+		expectedFormat Format
+		expectedError  string
+	}{
+		{"Empty", "", FormatJSON, ""},
+		{"JSON", "JSON", FormatJSON, ""},
+		{"Console", "console", FormatConsole, ""},
+		{"Invalid", "text", FormatJSON, `invalid format "text": must be one of json or console`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			format, err := ParseFormat(tc.format)
+			assert.Equal(t, tc.expectedFormat, format)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFormatText(t *testing.T) {
+	tests := []struct {
+		name           string
+		format         Format
+		expectedString string
+		expectedError  string
+	}{
+		{"JSON", FormatJSON, "json", ""},
+		{"Console", FormatConsole, "console", ""},
+		{"Invalid", Format(2), "Format(2)", "invalid format 2: must be FormatJSON or FormatConsole"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedString, tc.format.String())
+
+			text, err := tc.format.MarshalText()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedString, string(text))
+
+			var format Format
+			assert.NoError(t, format.UnmarshalText(text))
+			assert.Equal(t, tc.format, format)
+		})
+	}
+}
+
+func TestLevelFormat_Config(t *testing.T) {
+	type config struct {
+		Level  Level  `json:"level"`
+		Format Format `json:"format"`
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		var c config
+		err := json.Unmarshal([]byte(`{"level":"Debug","format":"console"}`), &c)
+		assert.NoError(t, err)
+		assert.Equal(t, config{LevelDebug, FormatConsole}, c)
+
+		b, err := json.Marshal(c)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"level":"debug","format":"console"}`, string(b))
+
+		err = json.Unmarshal([]byte(`{"level":"verbose"}`), &c)
+		assert.Error(t, err)
+	})
+
+	t.Run("Flag", func(t *testing.T) {
+		level, format := LevelInfo, FormatJSON
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+		fs.Var(&level, "log.level", "logging level")
+		fs.Var(&format, "log.format", "logging format")
+
+		err := fs.Parse([]string{"-log.level", "warn", "-log.format", "console"})
+		assert.NoError(t, err)
+		assert.Equal(t, LevelWarn, level)
+		assert.Equal(t, FormatConsole, format)
+
+		err = fs.Parse([]string{"-log.level", "verbose"})
+		assert.Error(t, err)
+		assert.Equal(t, LevelWarn, level)
+	})
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          Options
+		expectedError string
+	}{
+		{
+			name: "Default",
+			opts: Options{},
+		},
+		{
+			name: "Valid",
+			opts: Options{
+				Level:            "Debug",
+				Format:           FormatConsole,
+				OutputPaths:      []string{"stdout", "file:///var/log/app.log"},
+				Writers:          []io.Writer{new(bytes.Buffer)},
+				ErrorOutputPaths: []string{"stderr"},
+				Rotate:           &RotateOptions{Filename: "app.log", MaxSize: 1024},
+				Routes: []Route{
+					{From: LevelWarn, To: LevelError, OutputPaths: []string{"stderr"}},
+				},
+				BufferSize:     4096,
+				FlushInterval:  time.Second,
+				Async:          &AsyncOptions{Overflow: OverflowDropBelow, DropLevel: LevelWarn},
+				FlightRecorder: 100,
+			},
+		},
+		{
+			name:          "InvalidLevel",
+			opts:          Options{Level: "warning"},
+			expectedError: `invalid level "warning": must be one of trace, debug, info, warn, error, or none`,
+		},
+		{
+			name:          "InvalidLevelSpec",
+			opts:          Options{LevelSpec: "info,db=dbg"},
+			expectedError: `invalid level spec entry "db=dbg": invalid level "dbg": must be one of trace, debug, info, warn, error, or none`,
+		},
+		{
+			name:          "InvalidFormat",
+			opts:          Options{Format: Format(2)},
+			expectedError: "invalid format 2: must be FormatJSON or FormatConsole",
+		},
+		{
+			name:          "InvalidOutputPath",
+			opts:          Options{OutputPaths: []string{"http://example.com/logs"}},
+			expectedError: `invalid output path "http://example.com/logs": unsupported scheme "http"`,
+		},
+		{
+			name:          "NilErrorWriter",
+			opts:          Options{ErrorWriters: []io.Writer{nil}},
+			expectedError: "invalid writer 0: writer cannot be nil",
+		},
+		{
+			name:          "InvalidRotate",
+			opts:          Options{Rotate: &RotateOptions{}},
+			expectedError: "rotating file name cannot be empty",
+		},
+		{
+			name: "InvalidRoute",
+			opts: Options{
+				Routes: []Route{
+					{From: LevelWarn, To: LevelError},
+					{From: LevelNone, To: LevelError},
+				},
+			},
+			expectedError: "invalid route 1: invalid route level 0: must be between LevelError and LevelTrace",
+		},
+		{
+			name:          "InvalidBuffer",
+			opts:          Options{BufferSize: -1, FlushInterval: -time.Second},
+			expectedError: "invalid buffer size -1: cannot be negative; invalid flush interval -1s: cannot be negative",
+		},
+		{
+			name:          "InvalidAsync",
+			opts:          Options{Async: &AsyncOptions{QueueSize: -1}},
+			expectedError: "invalid async queue size -1: cannot be negative",
+		},
+		{
+			name:          "InvalidFlightRecorder",
+			opts:          Options{FlightRecorder: -1},
+			expectedError: "invalid flight recorder size -1: cannot be negative",
+		},
+		{
+			name:          "MultipleErrors",
+			opts:          Options{Level: "dbg", Format: Format(-1)},
+			expectedError: `invalid level "dbg": must be one of trace, debug, info, warn, error, or none; invalid format -1: must be FormatJSON or FormatConsole`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
 		})
 	}
 }
@@ -109,6 +512,7 @@ func TestNopLogger(t *testing.T) {
 	assert.NotNil(t, logger)
 
 	logger.With()
+	logger.WithLevel(LevelDebug)
 	logger.GetLevel()
 	logger.SetLevel("none")
 	logger.Debug("debug", "key", "value")
@@ -119,5 +523,348 @@ func TestNopLogger(t *testing.T) {
 	logger.Warnf("warn %s", "this")
 	logger.Debug("error", "key", "value")
 	logger.Errorf("error %s", "this")
+
+	// The nop logger deliberately does not exit the process after fatal logs.
+	logger.Fatal("fatal", "key", "value")
+	logger.Fatalf("fatal %s", "this")
+	assert.PanicsWithValue(t, "panic", func() { logger.Panic("panic") })
 	logger.Close()
 }
+
+func TestConcurrency_Loggers(t *testing.T) {
+	levels := []string{"none", "error", "warn", "info", "debug"}
+
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := tc.newLogger(Options{
+				Writers:      []io.Writer{ioutil.Discard},
+				ErrorWriters: []io.Writer{ioutil.Discard},
+			})
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(4)
+
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						logger.SetLevel(levels[(i+j)%len(levels)])
+					}
+				}(i)
+
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						level := logger.GetLevel()
+						assert.True(t, LevelNone <= level && level <= LevelDebug)
+					}
+				}()
+
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						child := logger.With("goroutine", i, "iteration", j)
+						child.SetLevel(levels[j%len(levels)])
+						child.Info("child message")
+					}
+				}(i)
+
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						logger.Debug("debug message", "iteration", j)
+						logger.Infof("info message %d", j)
+						logger.Warn("warn message", "iteration", j)
+						logger.Errorf("error message %d", j)
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			logger.SetLevel("warn")
+			assert.Equal(t, LevelWarn, logger.GetLevel())
+			assert.NoError(t, logger.Close())
+		})
+	}
+}
+
+func TestLevelPropagation_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			root := tc.newLogger(Options{
+				Level:   "info",
+				Writers: []io.Writer{buf},
+			})
+
+			child := root.With("logger", "child")
+			detached := root.WithLevel(LevelError)
+			grandchild := detached.With("logger", "grandchild")
+
+			assert.Equal(t, LevelInfo, child.GetLevel())
+			assert.Equal(t, LevelError, detached.GetLevel())
+			assert.Equal(t, LevelError, grandchild.GetLevel())
+
+			// Changing the level of the root changes the level of its children.
+			root.SetLevel("debug")
+			assert.Equal(t, LevelDebug, child.GetLevel())
+			assert.Equal(t, LevelError, detached.GetLevel())
+
+			child.Debug("child debug")
+			detached.Warn("detached warn")
+			assert.Contains(t, buf.String(), "child debug")
+			assert.NotContains(t, buf.String(), "detached warn")
+
+			// Changing the level of a child changes the level of its parent.
+			child.SetLevel("warn")
+			assert.Equal(t, LevelWarn, root.GetLevel())
+
+			root.Info("root info")
+			assert.NotContains(t, buf.String(), "root info")
+
+			// Changing the level of a detached logger changes the level of its children only.
+			grandchild.SetLevel("debug")
+			assert.Equal(t, LevelDebug, detached.GetLevel())
+			assert.Equal(t, LevelWarn, root.GetLevel())
+
+			detached.Debug("detached debug")
+			grandchild.Info("grandchild info")
+			assert.Contains(t, buf.String(), "detached debug")
+			assert.Contains(t, buf.String(), "grandchild info")
+
+			// Invalid levels are treated as none.
+			invalid := root.WithLevel(Level(42))
+			assert.Equal(t, LevelNone, invalid.GetLevel())
+			assert.False(t, invalid.Enabled(LevelError))
+
+			invalid.Error("invalid error")
+			assert.NotContains(t, buf.String(), "invalid error")
+
+			assert.NoError(t, root.Close())
+		})
+	}
+}
+
+func TestFatalPanic_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("Trace", func(t *testing.T) {
+				buf := new(bytes.Buffer)
+				logger := tc.newLogger(Options{
+					Level:   "debug",
+					Writers: []io.Writer{buf},
+				})
+
+				logger.Trace("first trace")
+				assert.NotContains(t, buf.String(), "first trace")
+
+				logger.SetLevel("trace")
+				assert.Equal(t, LevelTrace, logger.GetLevel())
+
+				logger.Trace("second trace", "key", "value")
+				logger.Tracef("third trace %d", 3)
+				assert.Contains(t, buf.String(), `"level":"trace"`)
+				assert.Contains(t, buf.String(), `"key":"value"`)
+				assert.Contains(t, buf.String(), "second trace")
+				assert.Contains(t, buf.String(), "third trace 3")
+				assert.Contains(t, buf.String(), "log_test.go:")
+
+				assert.NoError(t, logger.Close())
+			})
+
+			t.Run("Fatal", func(t *testing.T) {
+				var code int
+				buf := new(bytes.Buffer)
+				logger := tc.newLogger(Options{
+					Level:      "error",
+					Writers:    []io.Writer{buf},
+					BufferSize: 4096,
+					ExitFunc:   func(c int) { code = c },
+				})
+
+				logger.With("key", "value").Fatal("fatal message")
+				assert.Equal(t, 1, code)
+
+				// The buffered log is flushed before exiting.
+				assert.Contains(t, buf.String(), `"level":"fatal"`)
+				assert.Contains(t, buf.String(), `"key":"value"`)
+				assert.Contains(t, buf.String(), "fatal message")
+				assert.Contains(t, buf.String(), "log_test.go:")
+
+				code = 0
+				logger.WithLevel(LevelNone).Fatalf("fatal message %d", 2)
+				assert.Equal(t, 1, code)
+				assert.NotContains(t, buf.String(), "fatal message 2")
+
+				assert.NoError(t, logger.Close())
+			})
+
+			t.Run("Panic", func(t *testing.T) {
+				buf := new(bytes.Buffer)
+				logger := tc.newLogger(Options{
+					Level:   "error",
+					Writers: []io.Writer{buf},
+					Async:   &AsyncOptions{},
+				})
+
+				assert.PanicsWithValue(t, "panic message", func() {
+					logger.Panic("panic message", "key", "value")
+				})
+
+				// The async log is flushed before panicking.
+				assert.Contains(t, buf.String(), `"level":"panic"`)
+				assert.Contains(t, buf.String(), `"key":"value"`)
+				assert.Contains(t, buf.String(), "log_test.go:")
+
+				assert.PanicsWithValue(t, "panic message 2", func() {
+					logger.Panicf("panic message %d", 2)
+				})
+				assert.Contains(t, buf.String(), "panic message 2")
+
+				assert.NoError(t, logger.Close())
+			})
+		})
+	}
+}
+
+func TestSetLevelTo_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Level:   "info",
+				Writers: []io.Writer{buf},
+			})
+
+			for _, level := range []Level{LevelNone, LevelError, LevelWarn, LevelInfo, LevelDebug, LevelTrace} {
+				assert.NoError(t, logger.SetLevelTo(level))
+				assert.Equal(t, level, logger.GetLevel())
+			}
+
+			for _, level := range []Level{Level(-1), Level(6)} {
+				assert.EqualError(t, logger.SetLevelTo(level), fmt.Sprintf("invalid level %d: must be between LevelNone and LevelTrace", level))
+				assert.Equal(t, LevelTrace, logger.GetLevel())
+			}
+
+			// The string level names are parsed the same way.
+			logger.SetLevel("none")
+			assert.Equal(t, LevelNone, logger.GetLevel())
+			logger.SetLevel("invalid")
+			assert.Equal(t, LevelNone, logger.GetLevel())
+
+			logger.Error("error message")
+			assert.Empty(t, buf.String())
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+
+	t.Run("Nop", func(t *testing.T) {
+		logger := NewNopLogger()
+
+		assert.NoError(t, logger.SetLevelTo(LevelDebug))
+		assert.EqualError(t, logger.SetLevelTo(Level(6)), "invalid level 6: must be between LevelNone and LevelTrace")
+		assert.Equal(t, LevelNone, logger.GetLevel())
+	})
+}
+
+func TestLogLevel_Loggers(t *testing.T) {
+	for _, tc := range testBackends {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Level:   "debug",
+				Writers: []io.Writer{buf},
+			})
+
+			assert.False(t, logger.Enabled(LevelNone))
+			assert.True(t, logger.Enabled(LevelError))
+			assert.True(t, logger.Enabled(LevelWarn))
+			assert.True(t, logger.Enabled(LevelInfo))
+			assert.True(t, logger.Enabled(LevelDebug))
+			assert.False(t, logger.Enabled(LevelTrace))
+			assert.False(t, logger.Enabled(Level(9)))
+
+			for _, level := range []Level{LevelError, LevelWarn, LevelInfo, LevelDebug} {
+				buf.Reset()
+				logger.Log(level, "message", "key", "value")
+				assert.Contains(t, buf.String(), fmt.Sprintf(`"level":"%s"`, level))
+				assert.Contains(t, buf.String(), `"key":"value"`)
+				assert.Contains(t, buf.String(), "log_test.go:")
+
+				buf.Reset()
+				logger.Logf(level, "message %d", 1)
+				assert.Contains(t, buf.String(), fmt.Sprintf(`"level":"%s"`, level))
+				assert.Contains(t, buf.String(), "message 1")
+				assert.Contains(t, buf.String(), "log_test.go:")
+			}
+
+			buf.Reset()
+			logger.Log(LevelTrace, "message")
+			logger.Logf(LevelTrace, "message %d", 1)
+			logger.Log(LevelNone, "message")
+			logger.Logf(Level(9), "message %d", 1)
+			assert.Empty(t, buf.String())
+
+			logger.SetLevel("trace")
+			assert.True(t, logger.Enabled(LevelTrace))
+
+			logger.Log(LevelTrace, "message", "key", "value")
+			logger.Logf(LevelTrace, "message %d", 1)
+			assert.Equal(t, 2, strings.Count(buf.String(), `"level":"trace"`))
+			assert.Contains(t, buf.String(), "log_test.go:")
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+}
+
+func TestNamed_Loggers(t *testing.T) {
+	for _, tc := range testBackends {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("Root", func(t *testing.T) {
+				buf := new(bytes.Buffer)
+				root := tc.newLogger(Options{
+					Name:    "my-service",
+					Writers: []io.Writer{buf},
+				})
+
+				client := root.Named("http").With("requestId", "1234").Named("client")
+				db := root.Named("db")
+				unnamed := root.Named("")
+
+				client.Info("client message")
+				db.Info("db message")
+				unnamed.Info("root message")
+
+				lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+				assert.Len(t, lines, 3)
+				assert.Contains(t, lines[0], `"logger":"my-service.http.client"`)
+				assert.Contains(t, lines[0], `"requestId":"1234"`)
+				assert.Equal(t, 1, strings.Count(lines[0], `"logger"`))
+				assert.Contains(t, lines[1], `"logger":"my-service.db"`)
+				assert.Contains(t, lines[2], `"logger":"my-service"`)
+
+				// Named loggers share the level with their parents.
+				root.SetLevel("error")
+				assert.Equal(t, LevelError, client.GetLevel())
+
+				assert.NoError(t, root.Close())
+			})
+
+			t.Run("NoRoot", func(t *testing.T) {
+				buf := new(bytes.Buffer)
+				root := tc.newLogger(Options{
+					Writers: []io.Writer{buf},
+				})
+
+				root.Named("db").Named("pool").Info("pool message")
+				assert.Contains(t, buf.String(), `"logger":"db.pool"`)
+				assert.Contains(t, buf.String(), "log_test.go:")
+
+				assert.NoError(t, root.Close())
+			})
+		})
+	}
+}
@@ -0,0 +1,490 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// syncer is implemented by writers that buffer data and can be flushed.
+type syncer interface {
+	Sync() error
+}
+
+const defaultFlushInterval = time.Second
+
+// output is a destination for writing logs.
+// It combines a list of writers into one and it is concurrently safe to be used by multiple goroutines.
+// If size is set, writes are buffered up to size bytes and written to the writers when the output is flushed.
+type output struct {
+	mu      sync.Mutex
+	writers []io.Writer
+	closers []io.Closer
+	size    int
+	buf     []byte
+	closed  bool
+}
+
+// parsePath parses a path and returns either a standard stream or a file name.
+// A path can be "stdout", "stderr", a file URL (file:///var/log/app.log), or a file path.
+// Only file URLs are parsed as URLs and any other path is used as a file path as it is (app#1.log or C:\logs\app.log).
+func parsePath(path string) (io.Writer, string, error) {
+	switch path {
+	case "stdout":
+		return os.Stdout, "", nil
+	case "stderr":
+		return os.Stderr, "", nil
+	}
+
+	scheme := urlScheme(path)
+	if scheme != "" && scheme != "file" {
+		return nil, "", fmt.Errorf("invalid output path %q: unsupported scheme %q", path, scheme)
+	}
+
+	name := path
+
+	if scheme == "file" {
+		u, err := url.Parse(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid output path %q: %s", path, err)
+		}
+		if u.User != nil || u.Fragment != "" || u.RawQuery != "" || u.Port() != "" {
+			return nil, "", fmt.Errorf("invalid file URL %q: only a path is allowed", path)
+		}
+		if h := u.Hostname(); h != "" && h != "localhost" {
+			return nil, "", fmt.Errorf("invalid file URL %q: host must be empty or localhost", path)
+		}
+		name = u.Path
+	}
+
+	if name == "" {
+		return nil, "", fmt.Errorf("invalid output path %q: empty file path", path)
+	}
+
+	return nil, name, nil
+}
+
+// urlScheme returns the lowercase scheme of a path in the form of a URL with an authority (scheme://...).
+// It returns an empty string if the path is not in this form.
+func urlScheme(path string) string {
+	i := strings.Index(path, "://")
+	if i <= 0 {
+		return ""
+	}
+
+	for j, c := range path[:i] {
+		isLetter := ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+		isOther := ('0' <= c && c <= '9') || c == '+' || c == '-' || c == '.'
+		if !isLetter && (j == 0 || !isOther) {
+			return ""
+		}
+	}
+
+	return strings.ToLower(path[:i])
+}
+
+// openPath opens a writer for a path.
+// A path can be "stdout", "stderr", a file URL (file:///var/log/app.log), or a file path.
+func openPath(path string) (io.Writer, io.Closer, error) {
+	std, name, err := parsePath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if std != nil {
+		return std, nil, nil
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, f, nil
+}
+
+// validateSinks checks a list of paths and writers without opening them.
+func validateSinks(paths []string, writers []io.Writer) error {
+	var err error
+
+	for _, path := range paths {
+		_, _, e := parsePath(path)
+		err = multierr.Append(err, e)
+	}
+
+	for i, w := range writers {
+		if w == nil {
+			err = multierr.Append(err, fmt.Errorf("invalid writer %d: writer cannot be nil", i))
+		}
+	}
+
+	return err
+}
+
+// newOutput creates a new output for a list of paths and writers.
+// If no path and no writer is given, the default path will be used.
+func newOutput(paths []string, writers []io.Writer, defaultPath string) (*output, error) {
+	if len(paths) == 0 && len(writers) == 0 {
+		paths = []string{defaultPath}
+	}
+
+	o := &output{
+		writers: make([]io.Writer, 0, len(paths)+len(writers)),
+	}
+
+	for _, path := range paths {
+		w, c, err := openPath(path)
+		if err != nil {
+			for _, c := range o.closers {
+				_ = c.Close()
+			}
+			return nil, err
+		}
+
+		o.writers = append(o.writers, w)
+		if c != nil {
+			o.closers = append(o.closers, c)
+		}
+	}
+
+	o.writers = append(o.writers, writers...)
+
+	return o, nil
+}
+
+// buffered enables buffering writes up to size bytes.
+func (o *output) buffered(size int) {
+	o.size = size
+	o.buf = make([]byte, 0, size)
+}
+
+// Route routes the logs with levels between From and To (inclusive) to a list of output paths and writers.
+// Fatal and panic logs are routed as error logs.
+// For example, a route from LevelWarn to LevelError can be used for writing warnings and errors to stderr.
+type Route struct {
+	From        Level
+	To          Level
+	OutputPaths []string
+	Writers     []io.Writer
+}
+
+// matches determines whether or not a level is within the range of a route.
+func (r Route) matches(l Level) bool {
+	min, max := r.From, r.To
+	if min > max {
+		min, max = max, min
+	}
+	return min <= l && l <= max
+}
+
+// validate checks the levels, output paths, and writers of a route.
+func (r Route) validate() error {
+	var err error
+
+	for _, l := range []Level{r.From, r.To} {
+		if l < LevelError || l > LevelTrace {
+			err = multierr.Append(err, fmt.Errorf("invalid route level %d: must be between LevelError and LevelTrace", l))
+		}
+	}
+
+	return multierr.Append(err, validateSinks(r.OutputPaths, r.Writers))
+}
+
+// route is an opened Route.
+type route struct {
+	Route
+	out *output
+}
+
+// outputs are all outputs of a logger.
+// Logs are written to the outputs of the routes matching their levels and otherwise to the main output.
+// If async is set, logs are queued and written to the outputs asynchronously.
+// If the outputs are buffered, they are flushed periodically by a background goroutine.
+type outputs struct {
+	main   *output
+	routes []route
+	errOut *output
+	async  *asyncQueue
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// openOutputs creates all outputs for a set of options.
+func openOutputs(opts Options) (*outputs, error) {
+	o := new(outputs)
+	writers := opts.Writers
+
+	var rf *RotatingFile
+	if opts.Rotate != nil {
+		var err error
+		if rf, err = NewRotatingFile(*opts.Rotate); err != nil {
+			return nil, err
+		}
+		writers = append(writers[:len(writers):len(writers)], rf)
+	}
+
+	var err error
+	if o.main, err = newOutput(opts.OutputPaths, writers, "stdout"); err != nil {
+		if rf != nil {
+			_ = rf.Close()
+		}
+		return nil, err
+	}
+
+	if rf != nil {
+		o.main.closers = append(o.main.closers, rf)
+	}
+
+	for _, r := range opts.Routes {
+		out, err := newOutput(r.OutputPaths, r.Writers, "stdout")
+		if err != nil {
+			_ = o.Close()
+			return nil, err
+		}
+
+		o.routes = append(o.routes, route{
+			Route: r,
+			out:   out,
+		})
+	}
+
+	if o.errOut, err = newOutput(opts.ErrorOutputPaths, opts.ErrorWriters, "stderr"); err != nil {
+		_ = o.Close()
+		return nil, err
+	}
+
+	// The error output is not buffered, so internal errors are reported immediately.
+	if opts.BufferSize > 0 {
+		o.main.buffered(opts.BufferSize)
+		for _, r := range o.routes {
+			r.out.buffered(opts.BufferSize)
+		}
+
+		interval := opts.FlushInterval
+		if interval <= 0 {
+			interval = defaultFlushInterval
+		}
+
+		o.stop = make(chan struct{})
+		o.done = make(chan struct{})
+		go o.flushEvery(interval)
+	}
+
+	if opts.Async != nil {
+		o.async = newAsyncQueue(*opts.Async)
+	}
+
+	return o, nil
+}
+
+// flushEvery flushes the buffered outputs periodically until the outputs are closed.
+// Flush errors are reported to the error output.
+func (o *outputs) flushEvery(interval time.Duration) {
+	defer close(o.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+			if err := o.flush(); err != nil {
+				fmt.Fprintf(o.errOut, "%v write error: %v\n", time.Now().UTC(), err)
+			}
+		}
+	}
+}
+
+// flush writes the buffered data of all outputs to their writers.
+func (o *outputs) flush() error {
+	err := o.main.Flush()
+	for _, r := range o.routes {
+		err = multierr.Append(err, r.out.Flush())
+	}
+	return err
+}
+
+// routed determines whether or not a level is routed to any of the route outputs.
+func (o *outputs) routed(l Level) bool {
+	for _, r := range o.routes {
+		if r.matches(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sync writes all queued entries and flushes all outputs.
+func (o *outputs) Sync() error {
+	var err error
+
+	if o.async != nil {
+		o.async.sync()
+	}
+
+	if o.main != nil {
+		err = multierr.Append(err, o.main.Sync())
+	}
+
+	for _, r := range o.routes {
+		err = multierr.Append(err, r.out.Sync())
+	}
+
+	if o.errOut != nil {
+		err = multierr.Append(err, o.errOut.Sync())
+	}
+
+	return err
+}
+
+// Close writes all queued entries and flushes and closes all outputs.
+func (o *outputs) Close() error {
+	var err error
+
+	if o.async != nil {
+		err = multierr.Append(err, o.async.close())
+	}
+
+	if o.stop != nil {
+		close(o.stop)
+		<-o.done
+		o.stop = nil
+	}
+
+	if o.main != nil {
+		err = multierr.Append(err, o.main.Close())
+	}
+
+	for _, r := range o.routes {
+		err = multierr.Append(err, r.out.Close())
+	}
+
+	if o.errOut != nil {
+		err = multierr.Append(err, o.errOut.Close())
+	}
+
+	return err
+}
+
+// Write writes a log entry to all writers.
+// If the output is buffered, the log entry is written once the buffer is full or the output is flushed.
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.size == 0 || o.closed {
+		return o.write(p)
+	}
+
+	var err error
+	if len(o.buf)+len(p) > o.size {
+		err = o.flush()
+	}
+
+	// Log entries larger than the buffer are written directly.
+	if len(p) >= o.size {
+		if _, e := o.write(p); e != nil {
+			return 0, multierr.Append(err, e)
+		}
+		return len(p), err
+	}
+
+	o.buf = append(o.buf, p...)
+
+	return len(p), err
+}
+
+func (o *output) write(p []byte) (int, error) {
+	var err error
+	n := len(p)
+
+	for _, w := range o.writers {
+		m, e := w.Write(p)
+		err = multierr.Append(err, e)
+		if e == nil && m < n {
+			err = multierr.Append(err, io.ErrShortWrite)
+		}
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// Flush writes the buffered data to all writers.
+func (o *output) Flush() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.flush()
+}
+
+// flush writes the buffered data to all writers.
+// The buffered data is discarded even if writing fails, so a failing writer does not block the output.
+func (o *output) flush() error {
+	if len(o.buf) == 0 {
+		return nil
+	}
+
+	_, err := o.write(o.buf)
+	o.buf = o.buf[:0]
+
+	return err
+}
+
+// Sync flushes the buffered data and all writers that support flushing.
+// The standard streams are not synced since syncing them fails if they are not files.
+func (o *output) Sync() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return nil
+	}
+
+	return o.sync()
+}
+
+func (o *output) sync() error {
+	err := o.flush()
+
+	for _, w := range o.writers {
+		if w == os.Stdout || w == os.Stderr {
+			continue
+		}
+		if s, ok := w.(syncer); ok {
+			err = multierr.Append(err, s.Sync())
+		}
+	}
+
+	return err
+}
+
+// Close flushes all writers and closes the ones opened by the output.
+// Calling Close more than once has no effect.
+func (o *output) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return nil
+	}
+
+	err := o.sync()
+	for _, c := range o.closers {
+		err = multierr.Append(err, c.Close())
+	}
+	o.closed = true
+
+	return err
+}
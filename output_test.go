@@ -0,0 +1,857 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockWriter is a mock implementation of io.Writer.
+type mockWriter struct {
+	WriteInP      []byte
+	WriteOutN     int
+	WriteOutError error
+	SyncCalled    bool
+	SyncOutError  error
+}
+
+func (m *mockWriter) Write(p []byte) (int, error) {
+	// The data is copied since buffered outputs reuse their buffers.
+	m.WriteInP = append([]byte(nil), p...)
+	return m.WriteOutN, m.WriteOutError
+}
+
+func (m *mockWriter) Sync() error {
+	m.SyncCalled = true
+	return m.SyncOutError
+}
+
+// lockedBuffer is a bytes.Buffer that can be used concurrently.
+type lockedBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+func TestOpenPath(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedWriter io.Writer
+		expectedCloser bool
+		expectedName   string
+		expectedError  string
+	}{
+		{
+			name:           "Stdout",
+			path:           "stdout",
+			expectedWriter: os.Stdout,
+		},
+		{
+			name:           "Stderr",
+			path:           "stderr",
+			expectedWriter: os.Stderr,
+		},
+		{
+			name:           "FilePath",
+			path:           filepath.Join(dir, "path.log"),
+			expectedCloser: true,
+		},
+		{
+			name:           "FilePathWithHash",
+			path:           filepath.Join(dir, "app#1.log"),
+			expectedCloser: true,
+			expectedName:   filepath.Join(dir, "app#1.log"),
+		},
+		{
+			name:           "FilePathWithQuestionMark",
+			path:           filepath.Join(dir, "app?x.log"),
+			expectedCloser: true,
+			expectedName:   filepath.Join(dir, "app?x.log"),
+		},
+		{
+			name:           "FilePathWithPercent",
+			path:           filepath.Join(dir, "100%.log"),
+			expectedCloser: true,
+			expectedName:   filepath.Join(dir, "100%.log"),
+		},
+		{
+			name:           "FilePathWithColon",
+			path:           filepath.Join(dir, "app:1.log"),
+			expectedCloser: true,
+			expectedName:   filepath.Join(dir, "app:1.log"),
+		},
+		{
+			name:           "FileURL",
+			path:           "file://" + filepath.Join(dir, "url.log"),
+			expectedCloser: true,
+			expectedName:   filepath.Join(dir, "url.log"),
+		},
+		{
+			name:           "FileURLWithLocalhost",
+			path:           "file://localhost" + filepath.Join(dir, "localhost.log"),
+			expectedCloser: true,
+		},
+		{
+			name:          "InvalidURL",
+			path:          "file://%%",
+			expectedError: `invalid output path "file://%%"`,
+		},
+		{
+			name:          "UnsupportedScheme",
+			path:          "http://localhost:8080",
+			expectedError: `invalid output path "http://localhost:8080": unsupported scheme "http"`,
+		},
+		{
+			name:          "FileURLWithHost",
+			path:          "file://example.com/app.log",
+			expectedError: `invalid file URL "file://example.com/app.log": host must be empty or localhost`,
+		},
+		{
+			name:          "FileURLWithQuery",
+			path:          "file:///app.log?mode=rw",
+			expectedError: `invalid file URL "file:///app.log?mode=rw": only a path is allowed`,
+		},
+		{
+			name:          "EmptyFilePath",
+			path:          "file://",
+			expectedError: `invalid output path "file://": empty file path`,
+		},
+		{
+			name:          "NoDirectory",
+			path:          filepath.Join(dir, "missing", "app.log"),
+			expectedError: "no such file or directory",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w, c, err := openPath(tc.path)
+
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, w)
+				assert.Nil(t, c)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, w)
+				if tc.expectedWriter != nil {
+					assert.Equal(t, tc.expectedWriter, w)
+				}
+				if tc.expectedName != "" {
+					assert.Equal(t, tc.expectedName, w.(*os.File).Name())
+				}
+				if tc.expectedCloser {
+					assert.NotNil(t, c)
+					assert.NoError(t, c.Close())
+				} else {
+					assert.Nil(t, c)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSinks(t *testing.T) {
+	tests := []struct {
+		name          string
+		paths         []string
+		writers       []io.Writer
+		expectedError string
+	}{
+		{
+			name:    "Valid",
+			paths:   []string{"stdout", "stderr", "file:///var/log/app.log", "FILE:///var/log/app.log", "app.log", `C:\logs\app.log`, "logs/100%.log"},
+			writers: []io.Writer{new(bytes.Buffer)},
+		},
+		{
+			name:          "InvalidPaths",
+			paths:         []string{"file://example.com/app.log", ""},
+			expectedError: `invalid file URL "file://example.com/app.log": host must be empty or localhost; invalid output path "": empty file path`,
+		},
+		{
+			name:          "NilWriter",
+			writers:       []io.Writer{new(bytes.Buffer), nil},
+			expectedError: "invalid writer 1: writer cannot be nil",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSinks(tc.paths, tc.writers)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewOutput(t *testing.T) {
+	dir := t.TempDir()
+	buf := new(bytes.Buffer)
+
+	tests := []struct {
+		name            string
+		paths           []string
+		writers         []io.Writer
+		defaultPath     string
+		expectedWriters int
+		expectedClosers int
+		expectedError   string
+	}{
+		{
+			name:            "Default",
+			defaultPath:     "stdout",
+			expectedWriters: 1,
+			expectedClosers: 0,
+		},
+		{
+			name:            "Writers",
+			writers:         []io.Writer{buf},
+			defaultPath:     "stdout",
+			expectedWriters: 1,
+			expectedClosers: 0,
+		},
+		{
+			name:            "PathsAndWriters",
+			paths:           []string{"stderr", filepath.Join(dir, "app.log")},
+			writers:         []io.Writer{buf},
+			defaultPath:     "stdout",
+			expectedWriters: 3,
+			expectedClosers: 1,
+		},
+		{
+			name:          "InvalidPath",
+			paths:         []string{filepath.Join(dir, "app.log"), "http://localhost"},
+			defaultPath:   "stdout",
+			expectedError: `invalid output path "http://localhost": unsupported scheme "http"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := newOutput(tc.paths, tc.writers, tc.defaultPath)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, out)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, out.writers, tc.expectedWriters)
+				assert.Len(t, out.closers, tc.expectedClosers)
+			}
+		})
+	}
+}
+
+func TestOpenOutputs(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          Options
+		expectedError string
+	}{
+		{
+			name: "Default",
+			opts: Options{},
+		},
+		{
+			name: "InvalidOutputPath",
+			opts: Options{
+				OutputPaths: []string{"http://localhost"},
+			},
+			expectedError: `invalid output path "http://localhost": unsupported scheme "http"`,
+		},
+		{
+			name: "InvalidErrorOutputPath",
+			opts: Options{
+				ErrorOutputPaths: []string{"http://localhost"},
+			},
+			expectedError: `invalid output path "http://localhost": unsupported scheme "http"`,
+		},
+		{
+			name: "InvalidRotate",
+			opts: Options{
+				Rotate: &RotateOptions{},
+			},
+			expectedError: "rotating file name cannot be empty",
+		},
+		{
+			name: "InvalidRouteOutputPath",
+			opts: Options{
+				Rotate: &RotateOptions{
+					Filename: filepath.Join(t.TempDir(), "app.log"),
+				},
+				Routes: []Route{
+					{From: LevelWarn, To: LevelError, OutputPaths: []string{"http://localhost"}},
+				},
+			},
+			expectedError: `invalid output path "http://localhost": unsupported scheme "http"`,
+		},
+		{
+			name: "Routes",
+			opts: Options{
+				Routes: []Route{
+					{From: LevelDebug, To: LevelInfo, OutputPaths: []string{"stdout"}},
+					{From: LevelWarn, To: LevelError, OutputPaths: []string{"stderr"}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			outs, err := openOutputs(tc.opts)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, outs)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, outs.main)
+				assert.NotNil(t, outs.errOut)
+				assert.Len(t, outs.routes, len(tc.opts.Routes))
+				assert.NoError(t, outs.Close())
+			}
+		})
+	}
+}
+
+func TestRouteMatches(t *testing.T) {
+	tests := []struct {
+		name          string
+		route         Route
+		level         Level
+		expectedMatch bool
+	}{
+		{"Below", Route{From: LevelWarn, To: LevelError}, LevelNone, false},
+		{"From", Route{From: LevelWarn, To: LevelError}, LevelWarn, true},
+		{"To", Route{From: LevelWarn, To: LevelError}, LevelError, true},
+		{"Above", Route{From: LevelWarn, To: LevelError}, LevelInfo, false},
+		{"Reversed", Route{From: LevelDebug, To: LevelInfo}, LevelDebug, true},
+		{"Single", Route{From: LevelInfo, To: LevelInfo}, LevelInfo, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedMatch, tc.route.matches(tc.level))
+		})
+	}
+}
+
+func TestRouteValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		route         Route
+		expectedError string
+	}{
+		{
+			name:  "Valid",
+			route: Route{From: LevelDebug, To: LevelInfo, OutputPaths: []string{"stdout"}},
+		},
+		{
+			name:          "InvalidLevels",
+			route:         Route{From: LevelNone, To: Level(6)},
+			expectedError: "invalid route level 0: must be between LevelError and LevelTrace; invalid route level 6: must be between LevelError and LevelTrace",
+		},
+		{
+			name:          "InvalidPath",
+			route:         Route{From: LevelWarn, To: LevelError, OutputPaths: []string{"ftp://logs"}},
+			expectedError: `invalid output path "ftp://logs": unsupported scheme "ftp"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.route.validate()
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOutputsRouted(t *testing.T) {
+	outs := &outputs{
+		routes: []route{
+			{Route: Route{From: LevelWarn, To: LevelError}},
+		},
+	}
+
+	assert.True(t, outs.routed(LevelError))
+	assert.True(t, outs.routed(LevelWarn))
+	assert.False(t, outs.routed(LevelInfo))
+	assert.False(t, outs.routed(LevelDebug))
+}
+
+func TestOutputWrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		writers       []*mockWriter
+		p             []byte
+		expectedN     int
+		expectedError string
+	}{
+		{
+			name: "Success",
+			writers: []*mockWriter{
+				{WriteOutN: 5},
+				{WriteOutN: 5},
+			},
+			p:         []byte("entry"),
+			expectedN: 5,
+		},
+		{
+			name: "WriteError",
+			writers: []*mockWriter{
+				{WriteOutN: 5},
+				{WriteOutError: errors.New("write error")},
+			},
+			p:             []byte("entry"),
+			expectedN:     0,
+			expectedError: "write error",
+		},
+		{
+			name: "ShortWrite",
+			writers: []*mockWriter{
+				{WriteOutN: 2},
+			},
+			p:             []byte("entry"),
+			expectedN:     0,
+			expectedError: "short write",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := new(output)
+			for _, w := range tc.writers {
+				out.writers = append(out.writers, w)
+			}
+
+			n, err := out.Write(tc.p)
+
+			assert.Equal(t, tc.expectedN, n)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			for _, w := range tc.writers {
+				assert.Equal(t, tc.p, w.WriteInP)
+			}
+		})
+	}
+}
+
+func TestOutputWrite_Buffered(t *testing.T) {
+	tests := []struct {
+		name            string
+		size            int
+		writer          *mockWriter
+		writes          []string
+		expectedError   string
+		expectedWritten string
+		expectedBuffer  string
+	}{
+		{
+			name:            "Buffered",
+			size:            10,
+			writer:          &mockWriter{},
+			writes:          []string{"abc", "def"},
+			expectedWritten: "",
+			expectedBuffer:  "abcdef",
+		},
+		{
+			name:            "BufferFull",
+			size:            10,
+			writer:          &mockWriter{WriteOutN: 9},
+			writes:          []string{"abc", "def", "ghi", "jkl"},
+			expectedWritten: "abcdefghi",
+			expectedBuffer:  "jkl",
+		},
+		{
+			name:            "LargeEntry",
+			size:            4,
+			writer:          &mockWriter{WriteOutN: 6},
+			writes:          []string{"abcdef"},
+			expectedWritten: "abcdef",
+			expectedBuffer:  "",
+		},
+		{
+			name:            "FlushError",
+			size:            5,
+			writer:          &mockWriter{WriteOutError: errors.New("write error")},
+			writes:          []string{"abc", "def"},
+			expectedError:   "write error",
+			expectedWritten: "abc",
+			expectedBuffer:  "def",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &output{writers: []io.Writer{tc.writer}}
+			out.buffered(tc.size)
+
+			var err error
+			for _, w := range tc.writes {
+				if _, e := out.Write([]byte(w)); e != nil {
+					err = e
+				}
+			}
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expectedBuffer, string(out.buf))
+			if tc.expectedWritten != "" {
+				assert.Equal(t, tc.expectedWritten, string(tc.writer.WriteInP))
+			} else {
+				assert.Nil(t, tc.writer.WriteInP)
+			}
+		})
+	}
+}
+
+func TestOutputFlush(t *testing.T) {
+	buf := new(bytes.Buffer)
+	out := &output{writers: []io.Writer{buf}}
+	out.buffered(64)
+
+	_, err := out.Write([]byte("entry\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, out.Flush())
+	assert.Equal(t, "entry\n", buf.String())
+	assert.Empty(t, out.buf)
+
+	_, err = out.Write([]byte("synced\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, out.Sync())
+	assert.Equal(t, "entry\nsynced\n", buf.String())
+
+	_, err = out.Write([]byte("closed\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, out.Close())
+	assert.Equal(t, "entry\nsynced\nclosed\n", buf.String())
+
+	// Writes after closing are not buffered.
+	_, err = out.Write([]byte("late\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "entry\nsynced\nclosed\nlate\n", buf.String())
+}
+
+func TestOutputsFlushInterval(t *testing.T) {
+	buf := new(lockedBuffer)
+	outs, err := openOutputs(Options{
+		Writers:       []io.Writer{buf},
+		BufferSize:    1024,
+		FlushInterval: 10 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	_, err = outs.main.Write([]byte("entry\n"))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return buf.String() == "entry\n"
+	}, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, outs.Close())
+	assert.NoError(t, outs.Close())
+}
+
+func TestOutputSync(t *testing.T) {
+	tests := []struct {
+		name          string
+		writers       []io.Writer
+		expectedError string
+	}{
+		{
+			name:    "StandardStreams",
+			writers: []io.Writer{os.Stdout, os.Stderr},
+		},
+		{
+			name:    "Success",
+			writers: []io.Writer{new(bytes.Buffer), &mockWriter{}},
+		},
+		{
+			name:          "SyncError",
+			writers:       []io.Writer{&mockWriter{SyncOutError: errors.New("sync error")}},
+			expectedError: "sync error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &output{writers: tc.writers}
+
+			err := out.Sync()
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOutputClose(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name          string
+		paths         []string
+		writers       []io.Writer
+		expectedError string
+	}{
+		{
+			name:    "StandardStreams",
+			paths:   []string{"stdout", "stderr"},
+			writers: []io.Writer{new(bytes.Buffer)},
+		},
+		{
+			name:  "Files",
+			paths: []string{filepath.Join(dir, "app.log"), "file://" + filepath.Join(dir, "error.log")},
+		},
+		{
+			name:          "SyncError",
+			writers:       []io.Writer{&mockWriter{SyncOutError: errors.New("sync error")}},
+			expectedError: "sync error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := newOutput(tc.paths, tc.writers, "stdout")
+			assert.NoError(t, err)
+
+			err = out.Close()
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Closing and syncing a closed output should have no effect
+			assert.NoError(t, out.Close())
+			assert.NoError(t, out.Sync())
+		})
+	}
+}
+
+func TestOutputsSync(t *testing.T) {
+	tests := []struct {
+		name          string
+		outputs       *outputs
+		expectedError string
+	}{
+		{
+			name:    "Empty",
+			outputs: &outputs{},
+		},
+		{
+			name: "Success",
+			outputs: &outputs{
+				main:   &output{writers: []io.Writer{&mockWriter{}}},
+				routes: []route{{out: &output{writers: []io.Writer{&mockWriter{}}}}},
+				errOut: &output{writers: []io.Writer{&mockWriter{}}},
+			},
+		},
+		{
+			name: "Error",
+			outputs: &outputs{
+				main:   &output{writers: []io.Writer{&mockWriter{SyncOutError: errors.New("sync error")}}},
+				routes: []route{{out: &output{writers: []io.Writer{&mockWriter{SyncOutError: errors.New("sync error")}}}}},
+				errOut: &output{writers: []io.Writer{&mockWriter{}}},
+			},
+			expectedError: "sync error; sync error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.outputs.Sync()
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOutputsClose(t *testing.T) {
+	tests := []struct {
+		name          string
+		outputs       *outputs
+		expectedError string
+	}{
+		{
+			name:    "Empty",
+			outputs: &outputs{},
+		},
+		{
+			name: "Success",
+			outputs: &outputs{
+				main:   &output{writers: []io.Writer{&mockWriter{}}},
+				routes: []route{{out: &output{writers: []io.Writer{&mockWriter{}}}}},
+				errOut: &output{writers: []io.Writer{&mockWriter{}}},
+			},
+		},
+		{
+			name: "Error",
+			outputs: &outputs{
+				main:   &output{writers: []io.Writer{&mockWriter{SyncOutError: errors.New("sync error")}}},
+				routes: []route{{out: &output{writers: []io.Writer{&mockWriter{}}}}},
+				errOut: &output{writers: []io.Writer{&mockWriter{SyncOutError: errors.New("sync error")}}},
+			},
+			expectedError: "sync error; sync error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.outputs.Close()
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRoutes_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			main, low, high := new(bytes.Buffer), new(bytes.Buffer), new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Level:   "debug",
+				Writers: []io.Writer{main},
+				Routes: []Route{
+					{From: LevelDebug, To: LevelInfo, Writers: []io.Writer{low}},
+					{From: LevelWarn, To: LevelError, Writers: []io.Writer{high}},
+				},
+			})
+
+			logger.Debug("debug message")
+			logger.Info("info message")
+			logger.Warn("warn message")
+			logger.Error("error message")
+			assert.NoError(t, logger.Close())
+
+			assert.Empty(t, main.String())
+			assert.Contains(t, low.String(), "debug message")
+			assert.Contains(t, low.String(), "info message")
+			assert.NotContains(t, low.String(), "warn message")
+			assert.NotContains(t, low.String(), "error message")
+			assert.NotContains(t, high.String(), "debug message")
+			assert.NotContains(t, high.String(), "info message")
+			assert.Contains(t, high.String(), "warn message")
+			assert.Contains(t, high.String(), "error message")
+		})
+	}
+
+	for _, tc := range testLoggers {
+		t.Run(tc.name+"Unrouted", func(t *testing.T) {
+			main, high := new(bytes.Buffer), new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Level:   "info",
+				Writers: []io.Writer{main},
+				Routes: []Route{
+					{From: LevelWarn, To: LevelError, Writers: []io.Writer{high}},
+				},
+			})
+
+			logger.Debug("debug message")
+			logger.Info("info message")
+			logger.Error("error message")
+			assert.NoError(t, logger.Close())
+
+			assert.NotContains(t, main.String(), "debug message")
+			assert.Contains(t, main.String(), "info message")
+			assert.NotContains(t, main.String(), "error message")
+			assert.Contains(t, high.String(), "error message")
+		})
+	}
+}
+
+func TestBuffered_Loggers(t *testing.T) {
+	for _, tc := range testBackends {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(lockedBuffer)
+			logger := tc.newLogger(Options{
+				Writers:       []io.Writer{buf},
+				BufferSize:    4096,
+				FlushInterval: time.Hour,
+			})
+
+			logger.Info("first message")
+			child := logger.With("context", "test")
+			child.Info("second message")
+			assert.Empty(t, buf.String())
+
+			// Closing a child logger flushes the outputs without closing them.
+			assert.NoError(t, child.Close())
+			assert.Contains(t, buf.String(), "first message")
+			assert.Contains(t, buf.String(), "second message")
+
+			logger.Error("third message")
+			assert.NoError(t, logger.Close())
+			assert.Equal(t, 3, strings.Count(buf.String(), "\n"))
+			assert.Contains(t, buf.String(), "third message")
+		})
+	}
+
+	for _, tc := range testBackends {
+		t.Run(tc.name+"WriteError", func(t *testing.T) {
+			logger := tc.newLogger(Options{
+				Writers:      []io.Writer{&mockWriter{WriteOutError: errors.New("write error")}},
+				ErrorWriters: []io.Writer{new(bytes.Buffer)},
+				BufferSize:   4096,
+			})
+
+			logger.Info("message")
+
+			assert.EqualError(t, logger.Close(), "write error")
+		})
+	}
+}
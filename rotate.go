@@ -0,0 +1,454 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// RotateOptions are configurations for writing logs to a rotating file.
+//
+// The log file is rotated when its size exceeds MaxSize bytes and/or when it has been written to for Interval.
+// A rotated log file is renamed by adding the rotation time to its name (app-2020-04-24T12-39-04.506.log).
+// If a rotated log file with the same name already exists, a counter is added too (app-2020-04-24T12-39-04.506-1.log).
+// If Compress is true, rotated log files are compressed using gzip.
+// Rotated log files older than MaxAge and the ones exceeding MaxBackups are removed.
+// A zero value for MaxSize, Interval, MaxAge, or MaxBackups disables the corresponding feature.
+type RotateOptions struct {
+	Filename   string
+	MaxSize    int64
+	Interval   time.Duration
+	Compress   bool
+	MaxAge     time.Duration
+	MaxBackups int
+}
+
+// validate checks the options of a rotating file.
+func (o RotateOptions) validate() error {
+	var err error
+
+	if o.Filename == "" {
+		err = multierr.Append(err, errors.New("rotating file name cannot be empty"))
+	}
+
+	if o.MaxSize < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid rotating file max size %d: cannot be negative", o.MaxSize))
+	}
+
+	if o.Interval < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid rotating file interval %s: cannot be negative", o.Interval))
+	}
+
+	if o.MaxAge < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid rotating file max age %s: cannot be negative", o.MaxAge))
+	}
+
+	if o.MaxBackups < 0 {
+		err = multierr.Append(err, fmt.Errorf("invalid rotating file max backups %d: cannot be negative", o.MaxBackups))
+	}
+
+	return err
+}
+
+// RotatingFile is an io.WriteCloser that writes to a log file and rotates it.
+// It also reopens the log file when the process receives SIGHUP, so the log file can be rotated by external tools too.
+// It is concurrently safe to be used by multiple goroutines.
+type RotatingFile struct {
+	opts   RotateOptions
+	now    func() time.Time
+	rename func(string, string) error
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	rotateAt time.Time
+	closed   bool
+	errs     error
+
+	millMu sync.Mutex
+	millWG sync.WaitGroup
+
+	signals chan os.Signal
+	done    chan struct{}
+}
+
+// NewRotatingFile opens a log file for writing and rotating.
+func NewRotatingFile(opts RotateOptions) (*RotatingFile, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	f := &RotatingFile{
+		opts:    opts,
+		now:     time.Now,
+		rename:  os.Rename,
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	signal.Notify(f.signals, syscall.SIGHUP)
+	go f.handleSignals()
+
+	return f, nil
+}
+
+func (f *RotatingFile) handleSignals() {
+	for {
+		select {
+		case <-f.signals:
+			if err := f.Reopen(); err != nil {
+				f.mu.Lock()
+				f.errs = multierr.Append(f.errs, err)
+				f.mu.Unlock()
+			}
+		case <-f.done:
+			return
+		}
+	}
+}
+
+// open opens the log file for appending.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.opts.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+
+	if f.opts.Interval > 0 {
+		f.rotateAt = f.now().Add(f.opts.Interval)
+	}
+
+	return nil
+}
+
+// backupName returns the name of a rotated log file.
+// A counter is added to the name if a rotated log file, compressed or not, already exists with the same rotation time.
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir := filepath.Dir(f.opts.Filename)
+	ext := filepath.Ext(f.opts.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(f.opts.Filename), ext)
+	ts := t.UTC().Format(backupTimeFormat)
+
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("%s-%s%s", prefix, ts, ext)
+		if i > 0 {
+			name = fmt.Sprintf("%s-%s-%d%s", prefix, ts, i, ext)
+		}
+
+		path := filepath.Join(dir, name)
+		if !fileExists(path) && !fileExists(path+compressSuffix) {
+			return path
+		}
+	}
+}
+
+// fileExists determines whether or not a file exists.
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return !os.IsNotExist(err)
+}
+
+// ensureOpen opens the log file again if a previous rotation or reopen failed to open it.
+// It must be called with the lock held.
+func (f *RotatingFile) ensureOpen() error {
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	if f.file == nil {
+		return f.open()
+	}
+
+	return nil
+}
+
+// rotate closes the current log file, renames it, and opens a new one.
+// If the log file cannot be renamed, the original log file is opened again, so it can still be written to.
+// It must be called with the lock held.
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		err := f.file.Close()
+		f.file = nil
+		if err != nil {
+			return multierr.Append(err, f.open())
+		}
+	}
+
+	if err := f.rename(f.opts.Filename, f.backupName(f.now())); err != nil && !os.IsNotExist(err) {
+		return multierr.Append(err, f.open())
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.millWG.Add(1)
+	go func() {
+		defer f.millWG.Done()
+		if err := f.mill(); err != nil {
+			f.mu.Lock()
+			f.errs = multierr.Append(f.errs, err)
+			f.mu.Unlock()
+		}
+	}()
+
+	return nil
+}
+
+type backup struct {
+	path  string
+	time  time.Time
+	index int
+}
+
+// backups returns the rotated log files sorted from the newest to the oldest.
+func (f *RotatingFile) backups() ([]backup, error) {
+	dir := filepath.Dir(f.opts.Filename)
+	ext := filepath.Ext(f.opts.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(f.opts.Filename), ext) + "-"
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []backup{}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		ts := strings.TrimPrefix(name, prefix)
+		ts = strings.TrimSuffix(ts, compressSuffix)
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		ts = strings.TrimSuffix(ts, ext)
+
+		var index int
+		if len(ts) > len(backupTimeFormat) {
+			counter := ts[len(backupTimeFormat):]
+			if index, err = strconv.Atoi(strings.TrimPrefix(counter, "-")); err != nil || counter != "-"+strconv.Itoa(index) || index <= 0 {
+				continue
+			}
+			ts = ts[:len(backupTimeFormat)]
+		}
+
+		t, err := time.Parse(backupTimeFormat, ts)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backup{
+			path:  filepath.Join(dir, name),
+			time:  t,
+			index: index,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].time.Equal(backups[j].time) {
+			return backups[i].index > backups[j].index
+		}
+		return backups[i].time.After(backups[j].time)
+	})
+
+	return backups, nil
+}
+
+// mill compresses and removes rotated log files.
+func (f *RotatingFile) mill() error {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	cutoff := f.now().Add(-f.opts.MaxAge)
+
+	for i, b := range backups {
+		if (f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups) || (f.opts.MaxAge > 0 && b.time.Before(cutoff)) {
+			errs = multierr.Append(errs, os.Remove(b.path))
+			continue
+		}
+
+		if f.opts.Compress && !strings.HasSuffix(b.path, compressSuffix) {
+			errs = multierr.Append(errs, compressFile(b.path))
+		}
+	}
+
+	return errs
+}
+
+// compressFile compresses a file using gzip and removes the original file.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path + compressSuffix)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = zw.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	_ = src.Close()
+
+	return os.Remove(path)
+}
+
+// Write writes a log entry to the log file.
+// The log file is rotated before writing if needed.
+// If the rotation fails, the log entry is still written to the current log file and the rotation is retried on the next write.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.ensureOpen(); err != nil {
+		return 0, err
+	}
+
+	sizeExceeded := f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize
+	intervalPassed := f.opts.Interval > 0 && !f.now().Before(f.rotateAt)
+
+	var rotateErr error
+	if sizeExceeded || intervalPassed {
+		if rotateErr = f.rotate(); f.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, multierr.Append(rotateErr, err)
+}
+
+// Sync commits the current contents of the log file to stable storage.
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.ensureOpen(); err != nil {
+		return err
+	}
+
+	return f.file.Sync()
+}
+
+// Rotate rotates the log file immediately.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	return f.rotate()
+}
+
+// Reopen closes the log file and opens it again.
+// This is useful when the log file is renamed or removed by an external tool.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+
+	return multierr.Append(err, f.open())
+}
+
+// Close flushes and closes the log file.
+// It waits for compressing and removing rotated log files to finish.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+
+	if f.closed {
+		f.mu.Unlock()
+		return os.ErrClosed
+	}
+
+	f.closed = true
+	signal.Stop(f.signals)
+	close(f.done)
+
+	var err error
+	if f.file != nil {
+		err = multierr.Append(f.file.Sync(), f.file.Close())
+		f.file = nil
+	}
+
+	f.mu.Unlock()
+	f.millWG.Wait()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return multierr.Append(err, f.errs)
+}
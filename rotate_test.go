@@ -0,0 +1,399 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readGzipFile(t *testing.T, path string) string {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(zr)
+	assert.NoError(t, err)
+
+	return string(b)
+}
+
+func readFile(t *testing.T, path string) string {
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestNewRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name          string
+		opts          RotateOptions
+		expectedError string
+	}{
+		{
+			name:          "NoFilename",
+			opts:          RotateOptions{},
+			expectedError: "rotating file name cannot be empty",
+		},
+		{
+			name: "NegativeMaxSize",
+			opts: RotateOptions{
+				Filename: filepath.Join(dir, "app.log"),
+				MaxSize:  -1,
+			},
+			expectedError: "invalid rotating file max size -1: cannot be negative",
+		},
+		{
+			name: "NoDirectory",
+			opts: RotateOptions{
+				Filename: filepath.Join(dir, "missing", "app.log"),
+			},
+			expectedError: "no such file or directory",
+		},
+		{
+			name: "Success",
+			opts: RotateOptions{
+				Filename:   filepath.Join(dir, "app.log"),
+				MaxSize:    1024,
+				Interval:   time.Hour,
+				Compress:   true,
+				MaxAge:     24 * time.Hour,
+				MaxBackups: 10,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := NewRotatingFile(tc.opts)
+
+			if tc.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, f)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, f)
+				assert.NoError(t, f.Close())
+			}
+		})
+	}
+}
+
+func TestRotatingFile_Size(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	assert.NoError(t, ioutil.WriteFile(filename, []byte("first\n"), 0666))
+
+	f, err := NewRotatingFile(RotateOptions{
+		Filename: filename,
+		MaxSize:  10,
+	})
+	assert.NoError(t, err)
+
+	n, err := f.Write([]byte("second\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 7, n)
+
+	assert.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+	assert.Equal(t, "first\n", readFile(t, backups[0].path))
+	assert.Equal(t, "second\n", readFile(t, filename))
+}
+
+func TestRotatingFile_SameTime(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	f, err := NewRotatingFile(RotateOptions{
+		Filename: filename,
+		MaxSize:  8,
+	})
+	assert.NoError(t, err)
+
+	now := time.Date(2020, 4, 24, 12, 39, 4, 506000000, time.UTC)
+	f.now = func() time.Time {
+		return now
+	}
+
+	for _, entry := range []string{"AAAAAAA\n", "BBBBBBB\n", "CCCCCCC\n", "DDDDDDD\n"} {
+		_, err := f.Write([]byte(entry))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 3)
+	assert.Equal(t, filepath.Join(dir, "app-2020-04-24T12-39-04.506-2.log"), backups[0].path)
+	assert.Equal(t, "CCCCCCC\n", readFile(t, backups[0].path))
+	assert.Equal(t, filepath.Join(dir, "app-2020-04-24T12-39-04.506-1.log"), backups[1].path)
+	assert.Equal(t, "BBBBBBB\n", readFile(t, backups[1].path))
+	assert.Equal(t, filepath.Join(dir, "app-2020-04-24T12-39-04.506.log"), backups[2].path)
+	assert.Equal(t, "AAAAAAA\n", readFile(t, backups[2].path))
+	assert.Equal(t, "DDDDDDD\n", readFile(t, filename))
+}
+
+func TestRotatingFile_Interval(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	f, err := NewRotatingFile(RotateOptions{
+		Filename: filename,
+		Interval: time.Hour,
+	})
+	assert.NoError(t, err)
+
+	// The clock is also read by the background goroutine compressing and removing backups.
+	var mu sync.Mutex
+	now := time.Now()
+	f.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	_, err = f.Write([]byte("first\n"))
+	assert.NoError(t, err)
+
+	advance(2 * time.Hour)
+	_, err = f.Write([]byte("second\n"))
+	assert.NoError(t, err)
+
+	advance(time.Minute)
+	_, err = f.Write([]byte("third\n"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+	assert.Equal(t, "first\n", readFile(t, backups[0].path))
+	assert.Equal(t, "second\nthird\n", readFile(t, filename))
+}
+
+func TestRotatingFile_Compress(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	f, err := NewRotatingFile(RotateOptions{
+		Filename: filename,
+		Compress: true,
+	})
+	assert.NoError(t, err)
+
+	_, err = f.Write([]byte("first\n"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.Rotate())
+	assert.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+	assert.Equal(t, compressSuffix, filepath.Ext(backups[0].path))
+	assert.Equal(t, "first\n", readGzipFile(t, backups[0].path))
+}
+
+func TestRotatingFile_Retention(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	now := time.Now()
+
+	f, err := NewRotatingFile(RotateOptions{
+		Filename:   filename,
+		MaxAge:     24 * time.Hour,
+		MaxBackups: 2,
+	})
+	assert.NoError(t, err)
+
+	// Too old
+	old := f.backupName(now.Add(-48 * time.Hour))
+	assert.NoError(t, ioutil.WriteFile(old, []byte("old\n"), 0666))
+
+	// Exceeding the maximum number of backups
+	for i := 3; i > 0; i-- {
+		name := f.backupName(now.Add(-time.Duration(i) * time.Hour))
+		assert.NoError(t, ioutil.WriteFile(name+compressSuffix, []byte("backup\n"), 0666))
+	}
+
+	// Not a backup
+	other := filepath.Join(dir, "app-latest.log")
+	assert.NoError(t, ioutil.WriteFile(other, []byte("other\n"), 0666))
+
+	assert.NoError(t, f.Rotate())
+	assert.NoError(t, f.Close())
+
+	backups, err := f.backups()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 2)
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, other)
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	moved := filepath.Join(dir, "app.log.1")
+
+	f, err := NewRotatingFile(RotateOptions{
+		Filename: filename,
+	})
+	assert.NoError(t, err)
+
+	_, err = f.Write([]byte("first\n"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.Rename(filename, moved))
+	f.signals <- syscall.SIGHUP
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filename)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = f.Write([]byte("second\n"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, "first\n", readFile(t, moved))
+	assert.Equal(t, "second\n", readFile(t, filename))
+}
+
+func TestRotatingFile_RotateError(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	f, err := NewRotatingFile(RotateOptions{
+		Filename: filename,
+		MaxSize:  8,
+	})
+	assert.NoError(t, err)
+
+	now := time.Date(2020, 4, 24, 12, 39, 4, 506000000, time.UTC)
+	f.now = func() time.Time {
+		return now
+	}
+
+	renameErr := errors.New("rename failed")
+	f.rename = func(oldpath, newpath string) error {
+		if renameErr != nil {
+			return renameErr
+		}
+		return os.Rename(oldpath, newpath)
+	}
+
+	_, err = f.Write([]byte("AAAAAAA\n"))
+	assert.NoError(t, err)
+
+	n, err := f.Write([]byte("BBBBBBB\n"))
+	assert.Equal(t, renameErr, err)
+	assert.Equal(t, 8, n)
+	assert.Equal(t, "AAAAAAA\nBBBBBBB\n", readFile(t, filename))
+
+	// The rotation is retried after the cause is removed.
+	renameErr = nil
+	_, err = f.Write([]byte("CCCCCCC\n"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, "AAAAAAA\nBBBBBBB\n", readFile(t, filepath.Join(dir, "app-2020-04-24T12-39-04.506.log")))
+	assert.Equal(t, "CCCCCCC\n", readFile(t, filename))
+}
+
+func TestRotatingFile_ReopenError(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	moved := filepath.Join(dir, "app.log.1")
+
+	f, err := NewRotatingFile(RotateOptions{
+		Filename: filename,
+	})
+	assert.NoError(t, err)
+
+	_, err = f.Write([]byte("first\n"))
+	assert.NoError(t, err)
+
+	// A directory at the log file path fails opening the log file.
+	assert.NoError(t, os.Rename(filename, moved))
+	assert.NoError(t, os.Mkdir(filename, 0777))
+	assert.Error(t, f.Reopen())
+
+	_, err = f.Write([]byte("second\n"))
+	assert.Error(t, err)
+
+	// The log file is opened again after the cause is removed.
+	assert.NoError(t, os.Remove(filename))
+	_, err = f.Write([]byte("third\n"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, "first\n", readFile(t, moved))
+	assert.Equal(t, "third\n", readFile(t, filename))
+}
+
+func TestRotatingFile_Closed(t *testing.T) {
+	f, err := NewRotatingFile(RotateOptions{
+		Filename: filepath.Join(t.TempDir(), "app.log"),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	// The signal handler is stopped.
+	select {
+	case <-f.done:
+	default:
+		t.Error("signal handler is not stopped")
+	}
+
+	_, err = f.Write([]byte("entry\n"))
+	assert.Equal(t, os.ErrClosed, err)
+	assert.Equal(t, os.ErrClosed, f.Sync())
+	assert.Equal(t, os.ErrClosed, f.Rotate())
+	assert.Equal(t, os.ErrClosed, f.Reopen())
+	assert.Equal(t, os.ErrClosed, f.Close())
+}
+
+func TestRotatingFile_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "app.log")
+			logger := tc.newLogger(Options{
+				Rotate: &RotateOptions{
+					Filename: filename,
+				},
+			})
+
+			logger.Info("operation succeeded")
+			assert.NoError(t, logger.Close())
+			assert.NoError(t, logger.Close())
+
+			assert.Contains(t, readFile(t, filename), `"message":"operation succeeded"`)
+		})
+	}
+}
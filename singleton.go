@@ -1,124 +1,248 @@
 package log
 
 import (
-	kitlog "github.com/go-kit/kit/log"
-	zaplog "go.uber.org/zap"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
 )
 
+// singletonHolder holds the singleton logger.
+// logger is the logger set by SetSingleton and caller is the same logger adjusted for being called by the package-level functions.
+// A holder is always stored in the atomic value, so a nil logger can be stored too.
+type singletonHolder struct {
+	logger Logger
+	caller Logger
+}
+
 // The singleton logger
-var singleton Logger
+var singleton atomic.Value
+
+func init() {
+	singleton.Store(new(singletonHolder))
+}
+
+// getSingleton returns the singleton logger adjusted for being called by the package-level functions.
+func getSingleton() Logger {
+	return singleton.Load().(*singletonHolder).caller
+}
 
 // SetSingleton updates the singleton logger.
+// It is concurrently safe to be called while the singleton logger is being used.
 func SetSingleton(l Logger) {
-	switch v := l.(type) {
-	case *kit:
-		base := kitlog.With(v.base, "caller", kitlog.Caller(singletonCallerDepth))
-		logger := new(kitlog.SwapLogger)
-		filtered := createFilteredLogger(base, v.level)
-		logger.Swap(filtered)
-
-		singleton = &kit{
-			level:  v.level,
-			base:   base,
-			logger: logger,
-		}
+	singleton.Store(newSingletonHolder(l))
+}
 
-	case *zap:
-		logger, _ := v.config.Build(
-			zaplog.AddCaller(),
-			zaplog.AddCallerSkip(singletonCallerSkip),
-		)
+// Singleton returns the current singleton logger.
+// It returns nil if the singleton logger is not set.
+func Singleton() Logger {
+	return singleton.Load().(*singletonHolder).logger
+}
 
-		singleton = &zap{
-			config:        v.config,
-			logger:        logger,
-			sugaredLogger: logger.Sugar(),
-		}
+// ReplaceSingleton replaces the singleton logger and returns a function for restoring the previous one.
+// This can be used in tests for replacing the singleton logger temporarily.
+//
+//	restore := log.ReplaceSingleton(logger)
+//	defer restore()
+func ReplaceSingleton(l Logger) (restore func()) {
+	prev := singleton.Load().(*singletonHolder)
+	singleton.Store(newSingletonHolder(l))
+
+	return func() {
+		singleton.Store(prev)
+	}
+}
 
-	default:
-		singleton = l
+// newSingletonHolder creates a holder for a logger.
+// The logger is adjusted by skipping the stack frame of the package-level functions for reporting the caller.
+// The adjusted logger keeps the context (key-value pairs, name, etc.) and shares the logging level with the given logger.
+func newSingletonHolder(l Logger) *singletonHolder {
+	return &singletonHolder{
+		logger: l,
+		caller: addCallerSkip(l, 1),
 	}
 }
 
 // GetLevel returns the current logging level of the singleton logger.
 func GetLevel() Level {
-	if singleton != nil {
-		return singleton.GetLevel()
+	if l := getSingleton(); l != nil {
+		return l.GetLevel()
 	}
 	return LevelNone
 }
 
 // SetLevel changes the logging level of the singleton logger.
 func SetLevel(level string) {
-	if singleton != nil {
-		singleton.SetLevel(level)
+	if l := getSingleton(); l != nil {
+		l.SetLevel(level)
+	}
+}
+
+// SetLevelTo changes the logging level of the singleton logger.
+// It returns an error if the level is invalid.
+func SetLevelTo(level Level) error {
+	if l := getSingleton(); l != nil {
+		return l.SetLevelTo(level)
+	}
+	return level.validate()
+}
+
+// SetLevelFor changes the logging level of the singleton logger for a duration.
+// It returns a function for changing the level back before the duration ends.
+func SetLevelFor(level Level, d time.Duration) (cancel func(), err error) {
+	if l := getSingleton(); l != nil {
+		return l.SetLevelFor(level, d)
+	}
+	return func() {}, validateLevelFor(level, d)
+}
+
+// Enabled determines whether or not the singleton logger logs in a given level.
+// It returns false if the singleton logger is not set.
+func Enabled(level Level) bool {
+	if l := getSingleton(); l != nil {
+		return l.Enabled(level)
+	}
+	return false
+}
+
+// Log logs a message and a list of key-value pairs in a given level using the singleton logger.
+func Log(level Level, message string, kv ...interface{}) {
+	if l := getSingleton(); l != nil {
+		l.Log(level, message, kv...)
+	}
+}
+
+// Logf formats and logs a message in a given level using the singleton logger.
+// It uses fmt.Sprintf() to log a message.
+func Logf(level Level, format string, v ...interface{}) {
+	if l := getSingleton(); l != nil {
+		l.Logf(level, format, v...)
+	}
+}
+
+// Trace logs a message and a list of key-value pairs in trace level using the singleton logger.
+func Trace(message string, kv ...interface{}) {
+	if l := getSingleton(); l != nil {
+		l.Trace(message, kv...)
+	}
+}
+
+// Tracef formats and logs a message in trace level using the singleton logger.
+// It uses fmt.Sprintf() to log a message.
+func Tracef(format string, v ...interface{}) {
+	if l := getSingleton(); l != nil {
+		l.Tracef(format, v...)
 	}
 }
 
 // Debug logs a message and a list of key-value pairs in debug level using the singleton logger.
 func Debug(message string, kv ...interface{}) {
-	if singleton != nil {
-		singleton.Debug(message, kv...)
+	if l := getSingleton(); l != nil {
+		l.Debug(message, kv...)
 	}
 }
 
 // Debugf formats and logs a message in debug level using the singleton logger.
 // It uses fmt.Sprintf() to log a message.
 func Debugf(format string, v ...interface{}) {
-	if singleton != nil {
-		singleton.Debugf(format, v...)
+	if l := getSingleton(); l != nil {
+		l.Debugf(format, v...)
 	}
 }
 
 // Info logs a message and a list of key-value pairs in info level using the singleton logger.
 func Info(message string, kv ...interface{}) {
-	if singleton != nil {
-		singleton.Info(message, kv...)
+	if l := getSingleton(); l != nil {
+		l.Info(message, kv...)
 	}
 }
 
 // Infof formats and logs a message in info level using the singleton logger.
 // It uses fmt.Sprintf() to log a message.
 func Infof(format string, v ...interface{}) {
-	if singleton != nil {
-		singleton.Infof(format, v...)
+	if l := getSingleton(); l != nil {
+		l.Infof(format, v...)
 	}
 }
 
 // Warn logs a message and a list of key-value pairs in warn level using the singleton logger.
 func Warn(message string, kv ...interface{}) {
-	if singleton != nil {
-		singleton.Warn(message, kv...)
+	if l := getSingleton(); l != nil {
+		l.Warn(message, kv...)
 	}
 }
 
 // Warnf formats and logs a message in warn level using the singleton logger.
 // It uses fmt.Sprintf() to log a message.
 func Warnf(format string, v ...interface{}) {
-	if singleton != nil {
-		singleton.Warnf(format, v...)
+	if l := getSingleton(); l != nil {
+		l.Warnf(format, v...)
 	}
 }
 
 // Error logs a message and a list of key-value pairs in error level using the singleton logger.
 func Error(message string, kv ...interface{}) {
-	if singleton != nil {
-		singleton.Error(message, kv...)
+	if l := getSingleton(); l != nil {
+		l.Error(message, kv...)
 	}
 }
 
 // Errorf formats and logs a message in error level using the singleton logger.
 // It uses fmt.Sprintf() to log a message.
 func Errorf(format string, v ...interface{}) {
-	if singleton != nil {
-		singleton.Errorf(format, v...)
+	if l := getSingleton(); l != nil {
+		l.Errorf(format, v...)
+	}
+}
+
+// Fatal logs a message and a list of key-value pairs in fatal level using the singleton logger.
+// It then flushes the logger and exits the process.
+// If the singleton logger is not set, it exits the process without logging.
+func Fatal(message string, kv ...interface{}) {
+	if l := getSingleton(); l != nil {
+		l.Fatal(message, kv...)
+		return
+	}
+	os.Exit(1)
+}
+
+// Fatalf formats and logs a message in fatal level using the singleton logger.
+// It uses fmt.Sprintf() to log a message.
+// It then flushes the logger and exits the process.
+// If the singleton logger is not set, it exits the process without logging.
+func Fatalf(format string, v ...interface{}) {
+	if l := getSingleton(); l != nil {
+		l.Fatalf(format, v...)
+		return
+	}
+	os.Exit(1)
+}
+
+// Panic logs a message and a list of key-value pairs in panic level using the singleton logger.
+// It then flushes the logger and panics.
+// If the singleton logger is not set, it panics without logging.
+func Panic(message string, kv ...interface{}) {
+	if l := getSingleton(); l != nil {
+		l.Panic(message, kv...)
+	}
+	panic(message)
+}
+
+// Panicf formats and logs a message in panic level using the singleton logger.
+// It uses fmt.Sprintf() to log a message.
+// It then flushes the logger and panics.
+// If the singleton logger is not set, it panics without logging.
+func Panicf(format string, v ...interface{}) {
+	if l := getSingleton(); l != nil {
+		l.Panicf(format, v...)
 	}
+	panic(fmt.Sprintf(format, v...))
 }
 
 // Close flushes the singleton logger.
 func Close() error {
-	if singleton != nil {
-		return singleton.Close()
+	if l := getSingleton(); l != nil {
+		return l.Close()
 	}
 	return nil
 }
@@ -1,8 +1,14 @@
 package log
 
 import (
+	"bytes"
 	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,6 +30,10 @@ func TestSetSingleton(t *testing.T) {
 			name:   "ZapLogger",
 			logger: NewZap(Options{}),
 		},
+		{
+			name:   "TeeLogger",
+			logger: NewTee(NewKit(Options{}), NewZap(Options{})),
+		},
 		{
 			name:   "MockLogger",
 			logger: &mockLogger{},
@@ -32,11 +42,108 @@ func TestSetSingleton(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
+			defer ReplaceSingleton(nil)()
+
 			SetSingleton(tc.logger)
+
+			assert.Equal(t, tc.logger, Singleton())
 		})
 	}
 }
 
+func TestSetSingleton_Loggers(t *testing.T) {
+	for _, tc := range testLoggers {
+		t.Run(tc.name, func(t *testing.T) {
+			defer ReplaceSingleton(nil)()
+
+			buf := new(bytes.Buffer)
+			root := tc.newLogger(Options{
+				Name:    "my-service",
+				Level:   "info",
+				Writers: []io.Writer{buf},
+			})
+
+			child := root.With("requestId", "1234")
+			SetSingleton(child)
+
+			Info("request received")
+
+			assert.Contains(t, buf.String(), `"logger":"my-service"`)
+			assert.Contains(t, buf.String(), `"requestId":"1234"`)
+			assert.Contains(t, buf.String(), "singleton_test.go:")
+			assert.NotContains(t, buf.String(), "singleton.go:")
+
+			// The singleton logger shares the level with the logger passed in.
+			root.SetLevel("error")
+			assert.Equal(t, LevelError, GetLevel())
+
+			Info("request processed")
+			assert.NotContains(t, buf.String(), "request processed")
+
+			SetLevel("debug")
+			assert.Equal(t, LevelDebug, child.GetLevel())
+
+			assert.NoError(t, root.Close())
+		})
+	}
+}
+
+func TestReplaceSingleton(t *testing.T) {
+	defer ReplaceSingleton(nil)()
+
+	first, second := &mockLogger{}, &mockLogger{}
+	SetSingleton(first)
+
+	restore := ReplaceSingleton(second)
+	assert.Equal(t, second, Singleton())
+
+	Info("replaced")
+	assert.Equal(t, "replaced", second.InfoInMessage)
+	assert.Empty(t, first.InfoInMessage)
+
+	restore()
+	assert.Equal(t, first, Singleton())
+
+	Info("restored")
+	assert.Equal(t, "restored", first.InfoInMessage)
+}
+
+func TestSingleton_Concurrency(t *testing.T) {
+	defer ReplaceSingleton(nil)()
+
+	loggers := []Logger{
+		NewKit(Options{Writers: []io.Writer{ioutil.Discard}}),
+		NewZap(Options{Writers: []io.Writer{ioutil.Discard}}),
+		NewNopLogger(),
+		nil,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				restore := ReplaceSingleton(loggers[(i+j)%len(loggers)])
+				SetSingleton(loggers[j%len(loggers)])
+				restore()
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Infof("message %d", j)
+				_ = GetLevel()
+				_ = Singleton()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
 func TestGetLevel(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -88,12 +195,14 @@ func TestGetLevel(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			if tc.mockLogger == nil {
-				singleton = nil
-			} else {
-				singleton = tc.mockLogger
+			var logger Logger
+			if tc.mockLogger != nil {
+				logger = tc.mockLogger
 			}
 
+			restore := ReplaceSingleton(logger)
+			defer restore()
+
 			level := GetLevel()
 
 			assert.Equal(t, tc.expectedLevel, level)
@@ -140,12 +249,14 @@ func TestSetLevel(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			if tc.mockLogger == nil {
-				singleton = nil
-			} else {
-				singleton = tc.mockLogger
+			var logger Logger
+			if tc.mockLogger != nil {
+				logger = tc.mockLogger
 			}
 
+			restore := ReplaceSingleton(logger)
+			defer restore()
+
 			SetLevel(tc.level)
 
 			if tc.mockLogger != nil {
@@ -155,6 +266,125 @@ func TestSetLevel(t *testing.T) {
 	}
 }
 
+func TestSetLevelTo(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockLogger    *mockLogger
+		level         Level
+		expectedError string
+	}{
+		{
+			name:       "NoSingleton",
+			mockLogger: nil,
+			level:      LevelDebug,
+		},
+		{
+			name:          "NoSingletonInvalidLevel",
+			mockLogger:    nil,
+			level:         Level(-1),
+			expectedError: "invalid level -1: must be between LevelNone and LevelTrace",
+		},
+		{
+			name:       "OK",
+			mockLogger: &mockLogger{},
+			level:      LevelWarn,
+		},
+		{
+			name:          "Error",
+			mockLogger:    &mockLogger{SetLevelToOutError: errors.New("level error")},
+			level:         LevelWarn,
+			expectedError: "level error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var logger Logger
+			if tc.mockLogger != nil {
+				logger = tc.mockLogger
+			}
+
+			restore := ReplaceSingleton(logger)
+			defer restore()
+
+			err := SetLevelTo(tc.level)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tc.mockLogger != nil {
+				assert.Equal(t, tc.level, tc.mockLogger.SetLevelToInLevel)
+			}
+		})
+	}
+}
+
+func TestSetLevelFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockLogger    *mockLogger
+		level         Level
+		duration      time.Duration
+		expectedError string
+	}{
+		{
+			name:       "NoSingleton",
+			mockLogger: nil,
+			level:      LevelDebug,
+			duration:   time.Minute,
+		},
+		{
+			name:          "NoSingletonInvalidDuration",
+			mockLogger:    nil,
+			level:         LevelDebug,
+			duration:      0,
+			expectedError: "invalid duration 0s: must be positive",
+		},
+		{
+			name:       "OK",
+			mockLogger: &mockLogger{},
+			level:      LevelTrace,
+			duration:   time.Minute,
+		},
+		{
+			name:          "Error",
+			mockLogger:    &mockLogger{SetLevelForOutError: errors.New("level error")},
+			level:         LevelTrace,
+			duration:      time.Minute,
+			expectedError: "level error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var logger Logger
+			if tc.mockLogger != nil {
+				logger = tc.mockLogger
+			}
+
+			restore := ReplaceSingleton(logger)
+			defer restore()
+
+			cancel, err := SetLevelFor(tc.level, tc.duration)
+
+			assert.NotNil(t, cancel)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tc.mockLogger != nil {
+				assert.Equal(t, tc.level, tc.mockLogger.SetLevelForInLevel)
+				assert.Equal(t, tc.duration, tc.mockLogger.SetLevelForInDuration)
+			}
+		})
+	}
+}
+
 func TestLog(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -176,12 +406,23 @@ func TestLog(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			if tc.mockLogger == nil {
-				singleton = nil
-			} else {
-				singleton = tc.mockLogger
+			var logger Logger
+			if tc.mockLogger != nil {
+				logger = tc.mockLogger
 			}
 
+			restore := ReplaceSingleton(logger)
+			defer restore()
+
+			t.Run("Trace", func(t *testing.T) {
+				Trace(tc.message, tc.kv...)
+
+				if tc.mockLogger != nil {
+					assert.Equal(t, tc.message, tc.mockLogger.TraceInMessage)
+					assert.Equal(t, tc.kv, tc.mockLogger.TraceInKV)
+				}
+			})
+
 			t.Run("Debug", func(t *testing.T) {
 				Debug(tc.message, tc.kv...)
 
@@ -242,12 +483,23 @@ func TestLogf(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			if tc.mockLogger == nil {
-				singleton = nil
-			} else {
-				singleton = tc.mockLogger
+			var logger Logger
+			if tc.mockLogger != nil {
+				logger = tc.mockLogger
 			}
 
+			restore := ReplaceSingleton(logger)
+			defer restore()
+
+			t.Run("Tracef", func(t *testing.T) {
+				Tracef(tc.format, tc.args...)
+
+				if tc.mockLogger != nil {
+					assert.Equal(t, tc.format, tc.mockLogger.TracefInFormat)
+					assert.Equal(t, tc.args, tc.mockLogger.TracefInArgs)
+				}
+			})
+
 			t.Run("Debugf", func(t *testing.T) {
 				Debugf(tc.format, tc.args...)
 
@@ -287,6 +539,120 @@ func TestLogf(t *testing.T) {
 	}
 }
 
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name            string
+		mockLogger      *mockLogger
+		expectedEnabled bool
+	}{
+		{
+			name:            "NoSingleton",
+			mockLogger:      nil,
+			expectedEnabled: false,
+		},
+		{
+			name:            "Disabled",
+			mockLogger:      &mockLogger{EnabledOutResult: false},
+			expectedEnabled: false,
+		},
+		{
+			name:            "Enabled",
+			mockLogger:      &mockLogger{EnabledOutResult: true},
+			expectedEnabled: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var logger Logger
+			if tc.mockLogger != nil {
+				logger = tc.mockLogger
+			}
+
+			restore := ReplaceSingleton(logger)
+			defer restore()
+
+			assert.Equal(t, tc.expectedEnabled, Enabled(LevelInfo))
+
+			if tc.mockLogger != nil {
+				assert.Equal(t, LevelInfo, tc.mockLogger.EnabledInLevel)
+			}
+		})
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	message := "operation succeeded"
+	kv := []interface{}{"operation", "test"}
+	format := "operation succeeded: %s"
+	args := []interface{}{"test"}
+
+	t.Run("NoSingleton", func(t *testing.T) {
+		defer ReplaceSingleton(nil)()
+
+		Log(LevelInfo, message, kv...)
+		Logf(LevelInfo, format, args...)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		m := &mockLogger{}
+		defer ReplaceSingleton(m)()
+
+		Log(LevelInfo, message, kv...)
+		Logf(LevelDebug, format, args...)
+
+		assert.Equal(t, LevelInfo, m.LogInLevel)
+		assert.Equal(t, message, m.LogInMessage)
+		assert.Equal(t, kv, m.LogInKV)
+		assert.Equal(t, LevelDebug, m.LogfInLevel)
+		assert.Equal(t, format, m.LogfInFormat)
+		assert.Equal(t, args, m.LogfInArgs)
+	})
+
+	t.Run("Caller", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		defer ReplaceSingleton(NewZap(Options{Writers: []io.Writer{buf}}))()
+
+		Log(LevelInfo, message, kv...)
+		Logf(LevelWarn, format, args...)
+
+		assert.Equal(t, 2, strings.Count(buf.String(), "singleton_test.go:"))
+	})
+}
+
+func TestFatalPanic(t *testing.T) {
+	message := "operation failed"
+	kv := []interface{}{"operation", "test"}
+	format := "operation failed: %s"
+	args := []interface{}{"test"}
+
+	t.Run("NoSingleton", func(t *testing.T) {
+		defer ReplaceSingleton(nil)()
+
+		assert.PanicsWithValue(t, message, func() { Panic(message, kv...) })
+		assert.PanicsWithValue(t, "operation failed: test", func() { Panicf(format, args...) })
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		m := &mockLogger{}
+		defer ReplaceSingleton(m)()
+
+		Fatal(message, kv...)
+		Fatalf(format, args...)
+		assert.PanicsWithValue(t, message, func() { Panic(message, kv...) })
+		assert.PanicsWithValue(t, "operation failed: test", func() { Panicf(format, args...) })
+
+		assert.Equal(t, message, m.FatalInMessage)
+		assert.Equal(t, kv, m.FatalInKV)
+		assert.Equal(t, format, m.FatalfInFormat)
+		assert.Equal(t, args, m.FatalfInArgs)
+		assert.Equal(t, message, m.PanicInMessage)
+		assert.Equal(t, kv, m.PanicInKV)
+		assert.Equal(t, format, m.PanicfInFormat)
+		assert.Equal(t, args, m.PanicfInArgs)
+	})
+}
+
 func TestClose(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -314,12 +680,14 @@ func TestClose(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			if tc.mockLogger == nil {
-				singleton = nil
-			} else {
-				singleton = tc.mockLogger
+			var logger Logger
+			if tc.mockLogger != nil {
+				logger = tc.mockLogger
 			}
 
+			restore := ReplaceSingleton(logger)
+			defer restore()
+
 			err := Close()
 
 			assert.Equal(t, tc.expectedError, err)
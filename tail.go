@@ -0,0 +1,467 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultTailMaxBytes is the default maximum size of the entries buffered by a tail logger.
+const DefaultTailMaxBytes = 64 * 1024
+
+// entry is a log entry recorded for being logged later.
+type entry struct {
+	level   Level
+	time    time.Time
+	pc      uintptr
+	file    string
+	line    int
+	message string
+	kv      []interface{}
+}
+
+// newEntry records a log entry with the current time and the caller at a given stack depth.
+// skip is the number of stack frames to skip above the caller of newEntry.
+func newEntry(skip int, level Level, message string, kv []interface{}) entry {
+	pc, file, line, _ := runtime.Caller(skip + 1)
+
+	return entry{
+		level:   level,
+		time:    time.Now(),
+		pc:      pc,
+		file:    file,
+		line:    line,
+		message: message,
+		kv:      kv,
+	}
+}
+
+// size returns the approximate size of the entry when it is logged.
+func (e entry) size() int {
+	n := len(e.message)
+	for _, v := range e.kv {
+		n += len(fmt.Sprint(v))
+	}
+	return n
+}
+
+// entryLogger is implemented by loggers that can log entries recorded earlier with their own timestamps and callers.
+type entryLogger interface {
+	logEntry(e entry)
+}
+
+// entryRecorder is implemented by loggers that can record entries buffered for being logged later in the flight recorder.
+type entryRecorder interface {
+	recordEntry(e entry)
+}
+
+// recordEntry records an entry buffered for being logged later in the flight recorder of a logger.
+// If the logger does not support recording entries, the entry is not recorded.
+func recordEntry(l Logger, e entry) {
+	if er, ok := l.(entryRecorder); ok {
+		er.recordEntry(e)
+	}
+}
+
+// logEntry logs an entry recorded earlier regardless of the logging level of a logger.
+// If the logger does not support logging recorded entries, the entry is logged with the current time and caller.
+func logEntry(l Logger, e entry) {
+	if el, ok := l.(entryLogger); ok {
+		el.logEntry(e)
+		return
+	}
+	forceLevel(l, e.level).Log(e.level, e.message, e.kv...)
+}
+
+// TailOptions are options for NewTailLogger.
+type TailOptions struct {
+	// Threshold is the least verbose level that is logged immediately.
+	// The entries in the levels more verbose than the threshold are buffered.
+	// The default threshold is LevelInfo, so the debug and trace entries are buffered.
+	Threshold Level
+
+	// MaxBytes is the maximum approximate size of the buffered entries.
+	// When it is exceeded, the oldest buffered entries are dropped.
+	// The default size is DefaultTailMaxBytes.
+	MaxBytes int
+}
+
+// TailLogger is a Logger that buffers the verbose entries and logs them only when they are needed.
+// Flush logs the buffered entries and Discard drops them.
+type TailLogger interface {
+	Logger
+	Flush()
+	Discard()
+}
+
+// tailRecord is an entry buffered by a tail logger and the logger for logging it.
+type tailRecord struct {
+	entry
+	logger Logger
+	size   int
+}
+
+// tailBuffer keeps the entries buffered by a tail logger and the loggers created from it.
+type tailBuffer struct {
+	sync.Mutex
+	maxBytes int
+	size     int
+	dropped  int
+	records  []tailRecord
+}
+
+// add buffers an entry and drops the oldest entries if the buffer is full.
+func (b *tailBuffer) add(r tailRecord) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.records = append(b.records, r)
+	b.size += r.size
+
+	i := 0
+	for ; b.size > b.maxBytes && i < len(b.records); i++ {
+		b.size -= b.records[i].size
+	}
+
+	if i > 0 {
+		b.dropped += i
+		b.records = append(b.records[:0:0], b.records[i:]...)
+	}
+}
+
+// reset empties the buffer and returns the buffered entries and the number of dropped entries.
+func (b *tailBuffer) reset() ([]tailRecord, int) {
+	b.Lock()
+	defer b.Unlock()
+
+	records, dropped := b.records, b.dropped
+	b.records, b.size, b.dropped = nil, 0, 0
+
+	return records, dropped
+}
+
+// tail is an implementation of TailLogger that buffers entries in memory.
+// logger reports the callers of the tail logger methods and skip is the number of extra stack frames to skip for the buffered entries.
+type tail struct {
+	logger    Logger
+	threshold Level
+	skip      int
+	buffer    *tailBuffer
+}
+
+// NewTailLogger creates a request-scoped logger that buffers the entries more verbose than a threshold level in memory.
+// The buffered entries are logged in order with their original timestamps and callers when an error entry is logged or Flush is called.
+// They are logged regardless of the logging level of the logger, so the debug entries of a failed request can be logged in production.
+// Otherwise, they are dropped when Discard or Close is called.
+//
+// The loggers created from a tail logger using With, WithLevel, and Named share the same buffer.
+// Close discards the buffered entries and it does not close the logger.
+func NewTailLogger(l Logger, opts TailOptions) TailLogger {
+	threshold := opts.Threshold
+	if threshold == LevelNone || threshold.validate() != nil {
+		threshold = LevelInfo
+	}
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultTailMaxBytes
+	}
+
+	return &tail{
+		logger:    addCallerSkip(l, 1),
+		threshold: threshold,
+		buffer: &tailBuffer{
+			maxBytes: maxBytes,
+		},
+	}
+}
+
+// with returns a copy of the tail logger that logs using another logger and shares the same buffer.
+func (t *tail) with(l Logger) *tail {
+	return &tail{
+		logger:    l,
+		threshold: t.threshold,
+		skip:      t.skip,
+		buffer:    t.buffer,
+	}
+}
+
+// buffered determines whether or not the entries in a given level are buffered.
+func (t *tail) buffered(level Level) bool {
+	return level > t.threshold && level <= LevelTrace
+}
+
+// record buffers an entry logged by the caller of the tail logger method calling record.
+// The entry is recorded in the flight recorder when it is buffered, so it is recorded even if it is discarded.
+func (t *tail) record(level Level, message string, kv []interface{}) {
+	e := newEntry(t.skip+2, level, message, kv)
+	recordEntry(t.logger, e)
+	t.buffer.add(tailRecord{
+		entry:  e,
+		logger: t.logger,
+		size:   e.size(),
+	})
+}
+
+// With returns a new logger that automatically logs the given set of key-value pairs.
+// The new logger shares the buffer with its parent.
+func (t *tail) With(kv ...interface{}) Logger {
+	return t.with(t.logger.With(kv...))
+}
+
+// WithLevel returns a new logger that has its own logging level independent of its parent.
+// The new logger shares the buffer with its parent.
+func (t *tail) WithLevel(level Level) Logger {
+	return t.with(t.logger.WithLevel(level))
+}
+
+// Named returns a new logger whose name is the name of the logger followed by a dot and the given name.
+// The new logger shares the buffer with its parent.
+func (t *tail) Named(name string) Logger {
+	return t.with(t.logger.Named(name))
+}
+
+// addCallerSkip returns a copy of the logger that skips extra stack frames for reporting the caller.
+func (t *tail) addCallerSkip(skip int) Logger {
+	c := t.with(addCallerSkip(t.logger, skip))
+	c.skip += skip
+	return c
+}
+
+// forceLevel returns a copy of the logger that logs the entries in a given level and less verbose levels regardless of its logging level.
+func (t *tail) forceLevel(level Level) Logger {
+	return t.with(forceLevel(t.logger, level))
+}
+
+// logEntry logs an entry recorded earlier regardless of the logging level.
+func (t *tail) logEntry(e entry) {
+	logEntry(t.logger, e)
+}
+
+// recordEntry records an entry buffered for being logged later in the flight recorder.
+func (t *tail) recordEntry(e entry) {
+	recordEntry(t.logger, e)
+}
+
+// exitFunc returns the function called for exiting the process after a fatal log.
+// A logger not implemented by this package is assumed to exit the process using os.Exit.
+// If the logger does not exit the process (e.g. the nop logger), it returns nil.
+func (t *tail) exitFunc() func(int) {
+	switch e := t.logger.(type) {
+	case *nopLogger:
+		return nil
+	case exiter:
+		return e.exitFunc()
+	default:
+		return os.Exit
+	}
+}
+
+// withExitFunc returns a copy of the logger that calls the given function for exiting the process after a fatal log.
+func (t *tail) withExitFunc(exit func(int)) Logger {
+	if e, ok := t.logger.(exiter); ok {
+		return t.with(e.withExitFunc(exit))
+	}
+	return t
+}
+
+// GetLevel returns the current logging level.
+func (t *tail) GetLevel() Level {
+	return t.logger.GetLevel()
+}
+
+// SetLevel changes the logging level of the logger.
+func (t *tail) SetLevel(level string) {
+	t.logger.SetLevel(level)
+}
+
+// SetLevelTo changes the logging level of the logger.
+func (t *tail) SetLevelTo(level Level) error {
+	return t.logger.SetLevelTo(level)
+}
+
+// SetLevelFor changes the logging level of the logger for a duration.
+func (t *tail) SetLevelFor(level Level, d time.Duration) (func(), error) {
+	return t.logger.SetLevelFor(level, d)
+}
+
+// Enabled determines whether or not the logger logs or buffers the entries in a given level.
+func (t *tail) Enabled(level Level) bool {
+	return t.buffered(level) || t.logger.Enabled(level)
+}
+
+// Flush logs the buffered entries in order regardless of the logging level and empties the buffer.
+// If any entries are dropped since the buffer is full, the number of dropped entries is logged first.
+func (t *tail) Flush() {
+	records, dropped := t.buffer.reset()
+
+	if dropped > 0 {
+		t.logger.Warn("tail logger dropped buffered entries", "dropped", dropped)
+	}
+
+	for _, r := range records {
+		logEntry(r.logger, r.entry)
+	}
+}
+
+// Discard drops the buffered entries.
+func (t *tail) Discard() {
+	_, _ = t.buffer.reset()
+}
+
+// Log logs or buffers a message and a list of key-value pairs in a given level.
+// The buffered entries are logged first if the level is error.
+func (t *tail) Log(level Level, message string, kv ...interface{}) {
+	switch {
+	case t.buffered(level):
+		t.record(level, message, kv)
+	case level == LevelError:
+		t.Flush()
+		t.logger.Log(level, message, kv...)
+	default:
+		t.logger.Log(level, message, kv...)
+	}
+}
+
+// Logf formats and logs or buffers a message in a given level.
+// It uses fmt.Sprintf() to log a message.
+// The buffered entries are logged first if the level is error.
+func (t *tail) Logf(level Level, format string, args ...interface{}) {
+	switch {
+	case t.buffered(level):
+		t.record(level, fmt.Sprintf(format, args...), nil)
+	case level == LevelError:
+		t.Flush()
+		t.logger.Logf(level, format, args...)
+	default:
+		t.logger.Logf(level, format, args...)
+	}
+}
+
+// Trace logs or buffers a message and a list of key-value pairs in trace level.
+func (t *tail) Trace(message string, kv ...interface{}) {
+	if t.buffered(LevelTrace) {
+		t.record(LevelTrace, message, kv)
+	} else {
+		t.logger.Trace(message, kv...)
+	}
+}
+
+// Tracef formats and logs or buffers a message in trace level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tail) Tracef(format string, args ...interface{}) {
+	if t.buffered(LevelTrace) {
+		t.record(LevelTrace, fmt.Sprintf(format, args...), nil)
+	} else {
+		t.logger.Tracef(format, args...)
+	}
+}
+
+// Debug logs or buffers a message and a list of key-value pairs in debug level.
+func (t *tail) Debug(message string, kv ...interface{}) {
+	if t.buffered(LevelDebug) {
+		t.record(LevelDebug, message, kv)
+	} else {
+		t.logger.Debug(message, kv...)
+	}
+}
+
+// Debugf formats and logs or buffers a message in debug level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tail) Debugf(format string, args ...interface{}) {
+	if t.buffered(LevelDebug) {
+		t.record(LevelDebug, fmt.Sprintf(format, args...), nil)
+	} else {
+		t.logger.Debugf(format, args...)
+	}
+}
+
+// Info logs or buffers a message and a list of key-value pairs in info level.
+func (t *tail) Info(message string, kv ...interface{}) {
+	if t.buffered(LevelInfo) {
+		t.record(LevelInfo, message, kv)
+	} else {
+		t.logger.Info(message, kv...)
+	}
+}
+
+// Infof formats and logs or buffers a message in info level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tail) Infof(format string, args ...interface{}) {
+	if t.buffered(LevelInfo) {
+		t.record(LevelInfo, fmt.Sprintf(format, args...), nil)
+	} else {
+		t.logger.Infof(format, args...)
+	}
+}
+
+// Warn logs or buffers a message and a list of key-value pairs in warn level.
+func (t *tail) Warn(message string, kv ...interface{}) {
+	if t.buffered(LevelWarn) {
+		t.record(LevelWarn, message, kv)
+	} else {
+		t.logger.Warn(message, kv...)
+	}
+}
+
+// Warnf formats and logs or buffers a message in warn level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tail) Warnf(format string, args ...interface{}) {
+	if t.buffered(LevelWarn) {
+		t.record(LevelWarn, fmt.Sprintf(format, args...), nil)
+	} else {
+		t.logger.Warnf(format, args...)
+	}
+}
+
+// Error logs the buffered entries and then a message and a list of key-value pairs in error level.
+func (t *tail) Error(message string, kv ...interface{}) {
+	t.Flush()
+	t.logger.Error(message, kv...)
+}
+
+// Errorf logs the buffered entries and then formats and logs a message in error level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tail) Errorf(format string, args ...interface{}) {
+	t.Flush()
+	t.logger.Errorf(format, args...)
+}
+
+// Fatal logs the buffered entries and then a message and a list of key-value pairs in fatal level.
+// It then flushes the logger and exits the process.
+func (t *tail) Fatal(message string, kv ...interface{}) {
+	t.Flush()
+	t.logger.Fatal(message, kv...)
+}
+
+// Fatalf logs the buffered entries and then formats and logs a message in fatal level.
+// It uses fmt.Sprintf() to log a message.
+// It then flushes the logger and exits the process.
+func (t *tail) Fatalf(format string, args ...interface{}) {
+	t.Flush()
+	t.logger.Fatalf(format, args...)
+}
+
+// Panic logs the buffered entries and then a message and a list of key-value pairs in panic level.
+// It then flushes the logger and panics with the message.
+func (t *tail) Panic(message string, kv ...interface{}) {
+	t.Flush()
+	t.logger.Panic(message, kv...)
+}
+
+// Panicf logs the buffered entries and then formats and logs a message in panic level.
+// It uses fmt.Sprintf() to log a message.
+// It then flushes the logger and panics with the message.
+func (t *tail) Panicf(format string, args ...interface{}) {
+	t.Flush()
+	t.logger.Panicf(format, args...)
+}
+
+// Close discards the buffered entries.
+// The logger the tail logger is created from is not closed.
+func (t *tail) Close() error {
+	t.Discard()
+	return nil
+}
@@ -0,0 +1,263 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// callerRegexp matches the callers of the log entries logged in this file.
+var callerRegexp = regexp.MustCompile(`"caller":"[^"]*tail_test.go:`)
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{maxBytes: 10}
+
+	b.add(tailRecord{entry: entry{message: "first"}, size: 4})
+	b.add(tailRecord{entry: entry{message: "second"}, size: 4})
+	assert.Equal(t, 8, b.size)
+	assert.Equal(t, 0, b.dropped)
+
+	b.add(tailRecord{entry: entry{message: "third"}, size: 4})
+	assert.Equal(t, 8, b.size)
+	assert.Equal(t, 1, b.dropped)
+
+	b.add(tailRecord{entry: entry{message: "large"}, size: 20})
+	assert.Equal(t, 0, b.size)
+	assert.Equal(t, 4, b.dropped)
+
+	b.add(tailRecord{entry: entry{message: "last"}, size: 4})
+	records, dropped := b.reset()
+	assert.Len(t, records, 1)
+	assert.Equal(t, "last", records[0].message)
+	assert.Equal(t, 4, dropped)
+
+	records, dropped = b.reset()
+	assert.Empty(t, records)
+	assert.Equal(t, 0, dropped)
+}
+
+func TestEntrySize(t *testing.T) {
+	e := entry{
+		message: "message",
+		kv:      []interface{}{"key", 1234, "error", errors.New("failed")},
+	}
+
+	assert.Equal(t, 25, e.size())
+}
+
+func TestNewTailLogger(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              TailOptions
+		expectedThreshold Level
+		expectedMaxBytes  int
+	}{
+		{
+			name:              "Defaults",
+			opts:              TailOptions{},
+			expectedThreshold: LevelInfo,
+			expectedMaxBytes:  DefaultTailMaxBytes,
+		},
+		{
+			name:              "InvalidThreshold",
+			opts:              TailOptions{Threshold: Level(6), MaxBytes: -1},
+			expectedThreshold: LevelInfo,
+			expectedMaxBytes:  DefaultTailMaxBytes,
+		},
+		{
+			name:              "Options",
+			opts:              TailOptions{Threshold: LevelError, MaxBytes: 1024},
+			expectedThreshold: LevelError,
+			expectedMaxBytes:  1024,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := NewTailLogger(NewNopLogger(), tc.opts)
+
+			assert.IsType(t, &tail{}, logger)
+			assert.Equal(t, tc.expectedThreshold, logger.(*tail).threshold)
+			assert.Equal(t, tc.expectedMaxBytes, logger.(*tail).buffer.maxBytes)
+		})
+	}
+}
+
+func TestTail(t *testing.T) {
+	m := &mockLogger{GetLevelOutLevel: LevelWarn, EnabledOutResult: false}
+	logger := NewTailLogger(m, TailOptions{Threshold: LevelWarn})
+
+	assert.Equal(t, LevelWarn, logger.GetLevel())
+	logger.SetLevel("debug")
+	assert.Equal(t, "debug", m.SetLevelInLevel)
+	assert.NoError(t, logger.SetLevelTo(LevelTrace))
+	assert.Equal(t, LevelTrace, m.SetLevelToInLevel)
+	_, err := logger.SetLevelFor(LevelDebug, time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, LevelDebug, m.SetLevelForInLevel)
+
+	assert.True(t, logger.Enabled(LevelInfo))
+	assert.True(t, logger.Enabled(LevelTrace))
+	assert.False(t, logger.Enabled(LevelWarn))
+	assert.False(t, logger.Enabled(LevelNone))
+
+	// Buffered entries are not logged until an error is logged.
+	logger.Info("info message")
+	logger.Warn("warn message")
+	assert.Empty(t, m.InfoInMessage)
+	assert.Equal(t, "warn message", m.WarnInMessage)
+
+	logger.Error("error message")
+	assert.Equal(t, LevelInfo, m.LogInLevel)
+	assert.Equal(t, "info message", m.LogInMessage)
+	assert.Equal(t, "error message", m.ErrorInMessage)
+
+	// The wrapped logger is assumed to exit the process using os.Exit, unless it is the nop logger.
+	assert.Equal(t, reflect.ValueOf(os.Exit).Pointer(), reflect.ValueOf(logger.(exiter).exitFunc()).Pointer())
+	assert.Nil(t, NewTailLogger(NewNopLogger(), TailOptions{}).(exiter).exitFunc())
+
+	assert.NoError(t, logger.Close())
+}
+
+func TestTail_Loggers(t *testing.T) {
+	// timestamp returns the timestamp of the first log entry with a given message.
+	timestamp := func(t *testing.T, out, message string) time.Time {
+		for _, line := range strings.Split(out, "\n") {
+			var e struct {
+				Message   string    `json:"message"`
+				Timestamp time.Time `json:"timestamp"`
+			}
+			if json.Unmarshal([]byte(line), &e) == nil && e.Message == message {
+				return e.Timestamp
+			}
+		}
+
+		t.Fatalf("no log entry with message %q", message)
+		return time.Time{}
+	}
+
+	for _, tc := range testBackends {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			root := tc.newLogger(Options{
+				Name:    "my-service",
+				Level:   "info",
+				Writers: []io.Writer{buf},
+			})
+
+			t.Run("Error", func(t *testing.T) {
+				buf.Reset()
+				logger := NewTailLogger(root, TailOptions{})
+
+				logger.Debug("debug message", "key", "value")
+				logger.Named("db").Tracef("trace message %d", 1)
+				logger.Info("info message")
+				assert.NotContains(t, buf.String(), "debug message")
+				assert.Contains(t, buf.String(), "info message")
+
+				time.Sleep(10 * time.Millisecond)
+				before := time.Now()
+				logger.With("request", "1234").Error("error message")
+
+				out := buf.String()
+				assert.Contains(t, out, `"key":"value"`)
+				assert.Contains(t, out, `"level":"trace"`)
+				assert.Contains(t, out, `"logger":"my-service.db"`)
+				assert.NotContains(t, out, "tail.go:")
+				assert.Len(t, callerRegexp.FindAllString(out, -1), 4)
+
+				// The buffered entries are logged in order before the error with their original timestamps.
+				assert.Less(t, strings.Index(out, "debug message"), strings.Index(out, "trace message 1"))
+				assert.Less(t, strings.Index(out, "trace message 1"), strings.Index(out, "error message"))
+				assert.True(t, timestamp(t, out, "debug message").Before(before))
+				assert.True(t, timestamp(t, out, "trace message 1").Before(before))
+				assert.False(t, timestamp(t, out, "error message").Before(before))
+
+				// The buffer is empty after logging an error.
+				buf.Reset()
+				logger.Error("another error")
+				assert.NotContains(t, buf.String(), "debug message")
+			})
+
+			t.Run("FlushDiscard", func(t *testing.T) {
+				buf.Reset()
+				logger := NewTailLogger(root, TailOptions{Threshold: LevelWarn})
+
+				logger.Info("discarded message")
+				logger.Discard()
+				logger.Infof("flushed message %d", 1)
+				logger.Log(LevelWarn, "warn message")
+				assert.Contains(t, buf.String(), "warn message")
+				assert.NotContains(t, buf.String(), "flushed message")
+
+				logger.Flush()
+				assert.NotContains(t, buf.String(), "discarded message")
+				assert.Contains(t, buf.String(), "flushed message 1")
+
+				logger.Debug("closed message")
+				assert.NoError(t, logger.Close())
+				logger.Flush()
+				assert.NotContains(t, buf.String(), "closed message")
+			})
+
+			t.Run("MaxBytes", func(t *testing.T) {
+				buf.Reset()
+				logger := NewTailLogger(root, TailOptions{MaxBytes: 30})
+
+				logger.Debug("message 1")
+				logger.Debug("message 2")
+				logger.Debug("message 3")
+				logger.Debug("message 4")
+				logger.Flush()
+
+				out := buf.String()
+				assert.Contains(t, out, `"dropped":1`)
+				assert.NotContains(t, out, "message 1")
+				assert.Contains(t, out, "message 2")
+				assert.Contains(t, out, "message 4")
+				assert.Less(t, strings.Index(out, "dropped"), strings.Index(out, "message 2"))
+			})
+
+			t.Run("Context", func(t *testing.T) {
+				buf.Reset()
+				logger := NewTailLogger(root, TailOptions{})
+
+				ctx := NewContext(WithContextFields(context.Background(), "request", "1234"), logger)
+				DebugContext(ctx, "debug context")
+				ErrorContext(ctx, "error context")
+
+				assert.Equal(t, 2, strings.Count(buf.String(), `"request":"1234"`))
+				assert.Len(t, callerRegexp.FindAllString(buf.String(), -1), 2)
+			})
+
+			assert.NoError(t, root.Close())
+		})
+	}
+
+	t.Run("Tee", func(t *testing.T) {
+		kitBuf, zapBuf := new(bytes.Buffer), new(bytes.Buffer)
+		logger := NewTailLogger(NewTee(
+			NewKit(Options{Writers: []io.Writer{kitBuf}}),
+			NewZap(Options{Writers: []io.Writer{zapBuf}}),
+		), TailOptions{})
+
+		logger.Debug("debug message")
+		logger.Error("error message")
+
+		for _, buf := range []*bytes.Buffer{kitBuf, zapBuf} {
+			assert.Contains(t, buf.String(), "debug message")
+			assert.Contains(t, buf.String(), "error message")
+			assert.NotContains(t, buf.String(), "tail.go:")
+		}
+	})
+}
@@ -0,0 +1,443 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// tee is an implementation of Logger that logs to multiple loggers.
+type tee struct {
+	loggers []Logger
+}
+
+// NewTee creates a logger that logs every entry to all of the given loggers.
+// This can be used for logging the same entries in different formats and to different outputs.
+func NewTee(loggers ...Logger) Logger {
+	t := &tee{
+		loggers: make([]Logger, len(loggers)),
+	}
+
+	// Skip the stack frames of the tee logger for reporting the caller.
+	for i, l := range loggers {
+		t.loggers[i] = addCallerSkip(l, 1)
+	}
+
+	return t
+}
+
+// With returns a new logger that automatically logs the given set of key-value pairs.
+// This can be used for creating a contextualized logger.
+func (t *tee) With(kv ...interface{}) Logger {
+	loggers := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		loggers[i] = l.With(kv...)
+	}
+
+	return &tee{
+		loggers: loggers,
+	}
+}
+
+// Named returns a new logger that appends the given name to the names of all loggers.
+func (t *tee) Named(name string) Logger {
+	loggers := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		loggers[i] = l.Named(name)
+	}
+
+	return &tee{
+		loggers: loggers,
+	}
+}
+
+// WithLevel returns a new logger that has its own logging level independent of its parent.
+// The logging level of all loggers is set to the given level.
+func (t *tee) WithLevel(level Level) Logger {
+	loggers := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		loggers[i] = l.WithLevel(level)
+	}
+
+	return &tee{
+		loggers: loggers,
+	}
+}
+
+// addCallerSkip returns a copy of the logger that skips extra stack frames for reporting the caller.
+func (t *tee) addCallerSkip(skip int) Logger {
+	loggers := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		loggers[i] = addCallerSkip(l, skip)
+	}
+
+	return &tee{
+		loggers: loggers,
+	}
+}
+
+// forceLevel returns a copy of the logger whose loggers log the entries in a given level regardless of their logging levels.
+func (t *tee) forceLevel(level Level) Logger {
+	loggers := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		loggers[i] = forceLevel(l, level)
+	}
+
+	return &tee{
+		loggers: loggers,
+	}
+}
+
+// logEntry logs an entry recorded earlier to all loggers regardless of their logging levels.
+func (t *tee) logEntry(e entry) {
+	for _, l := range t.loggers {
+		logEntry(l, e)
+	}
+}
+
+// recordEntry records an entry buffered for being logged later in the flight recorders of all loggers.
+func (t *tee) recordEntry(e entry) {
+	for _, l := range t.loggers {
+		recordEntry(l, e)
+	}
+}
+
+// exitFunc returns the function called for exiting the process after a fatal log.
+// It is the exit function of the first logger that exits the process.
+// Loggers not implemented by this package are assumed to exit the process using os.Exit.
+// If none of the loggers exits the process (e.g. nop loggers), it returns nil.
+func (t *tee) exitFunc() func(int) {
+	var exit func(int)
+	for _, l := range t.loggers {
+		switch e := l.(type) {
+		case *nopLogger:
+		case exiter:
+			if f := e.exitFunc(); f != nil {
+				return f
+			}
+		default:
+			exit = os.Exit
+		}
+	}
+	return exit
+}
+
+// withExitFunc returns a copy of the logger that calls the given function for exiting the process after a fatal log.
+func (t *tee) withExitFunc(exit func(int)) Logger {
+	loggers := make([]Logger, len(t.loggers))
+	for i, l := range t.loggers {
+		if e, ok := l.(exiter); ok {
+			loggers[i] = e.withExitFunc(exit)
+		} else {
+			loggers[i] = l
+		}
+	}
+
+	return &tee{
+		loggers: loggers,
+	}
+}
+
+// GetLevel returns the most verbose logging level of all loggers.
+func (t *tee) GetLevel() Level {
+	level := LevelNone
+	for _, l := range t.loggers {
+		if v := l.GetLevel(); v > level {
+			level = v
+		}
+	}
+
+	return level
+}
+
+// SetLevel changes the logging level of all loggers.
+func (t *tee) SetLevel(level string) {
+	for _, l := range t.loggers {
+		l.SetLevel(level)
+	}
+}
+
+// SetLevelTo changes the logging level of all loggers and returns all of their errors combined.
+// It returns an error without changing any level if the level is invalid.
+func (t *tee) SetLevelTo(level Level) error {
+	if err := level.validate(); err != nil {
+		return err
+	}
+
+	var err error
+	for _, l := range t.loggers {
+		err = multierr.Append(err, l.SetLevelTo(level))
+	}
+
+	return err
+}
+
+// SetLevelFor changes the logging level of all loggers for a duration and returns all of their errors combined.
+// The returned function changes the levels of all loggers back before the duration ends.
+// It returns an error without changing any level if the level or the duration is invalid.
+func (t *tee) SetLevelFor(level Level, d time.Duration) (func(), error) {
+	if err := validateLevelFor(level, d); err != nil {
+		return func() {}, err
+	}
+
+	var err error
+	cancels := make([]func(), 0, len(t.loggers))
+	for _, l := range t.loggers {
+		cancel, e := l.SetLevelFor(level, d)
+		err = multierr.Append(err, e)
+		if cancel != nil {
+			cancels = append(cancels, cancel)
+		}
+	}
+
+	return func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}, err
+}
+
+// Enabled determines whether or not any of the loggers logs in a given level.
+func (t *tee) Enabled(level Level) bool {
+	for _, l := range t.loggers {
+		if l.Enabled(level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Log logs a message and a list of key-value pairs in a given level.
+func (t *tee) Log(level Level, message string, kv ...interface{}) {
+	for _, l := range t.loggers {
+		l.Log(level, message, kv...)
+	}
+}
+
+// Logf formats and logs a message in a given level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tee) Logf(level Level, format string, args ...interface{}) {
+	for _, l := range t.loggers {
+		l.Logf(level, format, args...)
+	}
+}
+
+// namedLevels returns the most verbose level of each named logger of all loggers.
+func (t *tee) namedLevels() map[string]Level {
+	levels := map[string]Level{}
+	for _, l := range t.loggers {
+		if nl, ok := l.(namedLeveler); ok {
+			for n, level := range nl.namedLevels() {
+				if v, ok := levels[n]; !ok || level > v {
+					levels[n] = level
+				}
+			}
+		}
+	}
+
+	return levels
+}
+
+// hasNamedLevel determines whether or not any of the loggers has a named logger.
+func (t *tee) hasNamedLevel(name string) bool {
+	for _, l := range t.loggers {
+		if nl, ok := l.(namedLeveler); ok && nl.hasNamedLevel(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// saveLevel returns a function that changes the logging levels of all loggers back to their current levels.
+func (t *tee) saveLevel() func() {
+	restores := make([]func(), len(t.loggers))
+	for i, l := range t.loggers {
+		restores[i] = saveLevel(l)
+	}
+
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
+
+// setLevelSpec changes the logging levels of all loggers using a level spec.
+func (t *tee) setLevelSpec(spec levelSpec) {
+	for _, l := range t.loggers {
+		if ls, ok := l.(levelSpecSetter); ok {
+			ls.setLevelSpec(spec)
+		}
+	}
+}
+
+// Trace logs a message and a list of key-value pairs in trace level.
+func (t *tee) Trace(message string, kv ...interface{}) {
+	for _, l := range t.loggers {
+		l.Trace(message, kv...)
+	}
+}
+
+// Tracef formats and logs a message in trace level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tee) Tracef(format string, args ...interface{}) {
+	for _, l := range t.loggers {
+		l.Tracef(format, args...)
+	}
+}
+
+// Debug logs a message and a list of key-value pairs in debug level.
+func (t *tee) Debug(message string, kv ...interface{}) {
+	for _, l := range t.loggers {
+		l.Debug(message, kv...)
+	}
+}
+
+// Debugf formats and logs a message in debug level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tee) Debugf(format string, args ...interface{}) {
+	for _, l := range t.loggers {
+		l.Debugf(format, args...)
+	}
+}
+
+// Info logs a message and a list of key-value pairs in info level.
+func (t *tee) Info(message string, kv ...interface{}) {
+	for _, l := range t.loggers {
+		l.Info(message, kv...)
+	}
+}
+
+// Infof formats and logs a message in info level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tee) Infof(format string, args ...interface{}) {
+	for _, l := range t.loggers {
+		l.Infof(format, args...)
+	}
+}
+
+// Warn logs a message and a list of key-value pairs in warn level.
+func (t *tee) Warn(message string, kv ...interface{}) {
+	for _, l := range t.loggers {
+		l.Warn(message, kv...)
+	}
+}
+
+// Warnf formats and logs a message in warn level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tee) Warnf(format string, args ...interface{}) {
+	for _, l := range t.loggers {
+		l.Warnf(format, args...)
+	}
+}
+
+// Error logs a message and a list of key-value pairs in error level.
+func (t *tee) Error(message string, kv ...interface{}) {
+	for _, l := range t.loggers {
+		l.Error(message, kv...)
+	}
+}
+
+// Errorf formats and logs a message in error level.
+// It uses fmt.Sprintf() to log a message.
+func (t *tee) Errorf(format string, args ...interface{}) {
+	for _, l := range t.loggers {
+		l.Errorf(format, args...)
+	}
+}
+
+// Fatal logs a message and a list of key-value pairs in fatal level to all loggers.
+// It then exits the process once after all loggers are flushed, unless none of the loggers exits the process.
+func (t *tee) Fatal(message string, kv ...interface{}) {
+	exit := t.exitFunc()
+	others := make([]Logger, 0, len(t.loggers))
+	for _, l := range t.loggers {
+		if e, ok := l.(exiter); ok {
+			e.withExitFunc(func(int) {}).Fatal(message, kv...)
+		} else {
+			others = append(others, l)
+		}
+	}
+
+	// The other loggers may exit the process themselves, so they log after the loggers of this package are flushed.
+	for _, l := range others {
+		l.Fatal(message, kv...)
+	}
+
+	if exit != nil {
+		exit(1)
+	}
+}
+
+// Fatalf formats and logs a message in fatal level to all loggers.
+// It uses fmt.Sprintf() to log a message.
+// It then exits the process once after all loggers are flushed, unless none of the loggers exits the process.
+func (t *tee) Fatalf(format string, args ...interface{}) {
+	exit := t.exitFunc()
+	others := make([]Logger, 0, len(t.loggers))
+	for _, l := range t.loggers {
+		if e, ok := l.(exiter); ok {
+			e.withExitFunc(func(int) {}).Fatalf(format, args...)
+		} else {
+			others = append(others, l)
+		}
+	}
+
+	// The other loggers may exit the process themselves, so they log after the loggers of this package are flushed.
+	for _, l := range others {
+		l.Fatalf(format, args...)
+	}
+
+	if exit != nil {
+		exit(1)
+	}
+}
+
+// Panic logs a message and a list of key-value pairs in panic level to all loggers.
+// It then panics once after all loggers are flushed.
+func (t *tee) Panic(message string, kv ...interface{}) {
+	for _, l := range t.loggers {
+		// Skip the stack frames of recoverPanic and the function literal for reporting the caller.
+		l := addCallerSkip(l, 2)
+		recoverPanic(func() {
+			l.Panic(message, kv...)
+		})
+	}
+	panic(message)
+}
+
+// Panicf formats and logs a message in panic level to all loggers.
+// It uses fmt.Sprintf() to log a message.
+// It then panics once after all loggers are flushed.
+func (t *tee) Panicf(format string, args ...interface{}) {
+	for _, l := range t.loggers {
+		// Skip the stack frames of recoverPanic and the function literal for reporting the caller.
+		l := addCallerSkip(l, 2)
+		recoverPanic(func() {
+			l.Panicf(format, args...)
+		})
+	}
+	panic(fmt.Sprintf(format, args...))
+}
+
+// recoverPanic calls a function and recovers from its panic.
+func recoverPanic(f func()) {
+	defer func() {
+		_ = recover()
+	}()
+	f()
+}
+
+// Close flushes all loggers and returns all of their errors combined.
+func (t *tee) Close() error {
+	var err error
+	for _, l := range t.loggers {
+		err = multierr.Append(err, l.Close())
+	}
+
+	return err
+}
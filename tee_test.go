@@ -0,0 +1,457 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTee(t *testing.T) {
+	tests := []struct {
+		name    string
+		loggers []Logger
+	}{
+		{
+			name:    "NoLogger",
+			loggers: nil,
+		},
+		{
+			name: "Loggers",
+			loggers: []Logger{
+				NewNopLogger(),
+				NewKit(Options{}),
+				NewZap(Options{}),
+				&mockLogger{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := NewTee(tc.loggers...)
+
+			assert.NotNil(t, logger)
+			assert.IsType(t, &tee{}, logger)
+			assert.Len(t, logger.(*tee).loggers, len(tc.loggers))
+		})
+	}
+}
+
+func TestTeeCaller(t *testing.T) {
+	kitOut, zapOut := new(bytes.Buffer), new(bytes.Buffer)
+	logger := NewTee(
+		NewKit(Options{Writers: []io.Writer{kitOut}}),
+		NewZap(Options{Writers: []io.Writer{zapOut}}),
+	)
+
+	logger.Info("operation succeeded")
+	logger.With("context", "test").Infof("operation %s", "succeeded")
+
+	assert.Contains(t, kitOut.String(), `"caller":"tee_test.go:`)
+	assert.NotContains(t, kitOut.String(), `"caller":"tee.go:`)
+	assert.Contains(t, zapOut.String(), `/tee_test.go:`)
+	assert.NotContains(t, zapOut.String(), `/tee.go:`)
+}
+
+func TestTeeWith(t *testing.T) {
+	child1, child2 := &mockLogger{}, &mockLogger{}
+	logger := &tee{
+		loggers: []Logger{
+			&mockLogger{WithOutLogger: child1},
+			&mockLogger{WithOutLogger: child2},
+		},
+	}
+
+	kv := []interface{}{"version", "0.1.0"}
+	child := logger.With(kv...)
+
+	assert.Equal(t, &tee{loggers: []Logger{child1, child2}}, child)
+	for _, l := range logger.loggers {
+		assert.Equal(t, kv, l.(*mockLogger).WithInKV)
+	}
+}
+
+func TestTeeWithLevel(t *testing.T) {
+	child1, child2 := &mockLogger{}, &mockLogger{}
+	logger := &tee{
+		loggers: []Logger{
+			&mockLogger{WithLevelOutLogger: child1},
+			&mockLogger{WithLevelOutLogger: child2},
+		},
+	}
+
+	child := logger.WithLevel(LevelWarn)
+
+	assert.Equal(t, &tee{loggers: []Logger{child1, child2}}, child)
+	for _, l := range logger.loggers {
+		assert.Equal(t, LevelWarn, l.(*mockLogger).WithLevelInLevel)
+	}
+}
+
+func TestTeeNamed(t *testing.T) {
+	child1, child2 := &mockLogger{}, &mockLogger{}
+	logger := &tee{
+		loggers: []Logger{
+			&mockLogger{NamedOutLogger: child1},
+			&mockLogger{NamedOutLogger: child2},
+		},
+	}
+
+	child := logger.Named("db")
+
+	assert.Equal(t, &tee{loggers: []Logger{child1, child2}}, child)
+	for _, l := range logger.loggers {
+		assert.Equal(t, "db", l.(*mockLogger).NamedInName)
+	}
+}
+
+func TestTeeGetLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		loggers       []Logger
+		expectedLevel Level
+	}{
+		{
+			name:          "NoLogger",
+			loggers:       nil,
+			expectedLevel: LevelNone,
+		},
+		{
+			name: "MostVerbose",
+			loggers: []Logger{
+				&mockLogger{GetLevelOutLevel: LevelError},
+				&mockLogger{GetLevelOutLevel: LevelDebug},
+				&mockLogger{GetLevelOutLevel: LevelInfo},
+			},
+			expectedLevel: LevelDebug,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := &tee{loggers: tc.loggers}
+
+			assert.Equal(t, tc.expectedLevel, logger.GetLevel())
+		})
+	}
+}
+
+func TestTeeSetLevel(t *testing.T) {
+	m1, m2 := &mockLogger{}, &mockLogger{}
+	logger := &tee{loggers: []Logger{m1, m2}}
+
+	logger.SetLevel("debug")
+
+	assert.Equal(t, "debug", m1.SetLevelInLevel)
+	assert.Equal(t, "debug", m2.SetLevelInLevel)
+}
+
+func TestTeeSetLevelTo(t *testing.T) {
+	tests := []struct {
+		name          string
+		loggers       []*mockLogger
+		level         Level
+		expectedLevel Level
+		expectedError string
+	}{
+		{
+			name:          "OK",
+			loggers:       []*mockLogger{{}, {}},
+			level:         LevelDebug,
+			expectedLevel: LevelDebug,
+		},
+		{
+			name:          "InvalidLevel",
+			loggers:       []*mockLogger{{}, {}},
+			level:         Level(9),
+			expectedLevel: LevelNone,
+			expectedError: "invalid level 9: must be between LevelNone and LevelTrace",
+		},
+		{
+			name:          "LoggerError",
+			loggers:       []*mockLogger{{SetLevelToOutError: errors.New("level error")}, {}},
+			level:         LevelWarn,
+			expectedLevel: LevelWarn,
+			expectedError: "level error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := &tee{}
+			for _, m := range tc.loggers {
+				logger.loggers = append(logger.loggers, m)
+			}
+
+			err := logger.SetLevelTo(tc.level)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			for _, m := range tc.loggers {
+				assert.Equal(t, tc.expectedLevel, m.SetLevelToInLevel)
+			}
+		})
+	}
+}
+
+func TestTeeSetLevelFor(t *testing.T) {
+	tests := []struct {
+		name             string
+		loggers          []*mockLogger
+		level            Level
+		duration         time.Duration
+		expectedLevel    Level
+		expectedDuration time.Duration
+		expectedError    string
+	}{
+		{
+			name:             "OK",
+			loggers:          []*mockLogger{{}, {}},
+			level:            LevelDebug,
+			duration:         time.Minute,
+			expectedLevel:    LevelDebug,
+			expectedDuration: time.Minute,
+		},
+		{
+			name:          "InvalidLevel",
+			loggers:       []*mockLogger{{}, {}},
+			level:         Level(9),
+			duration:      time.Minute,
+			expectedError: "invalid level 9: must be between LevelNone and LevelTrace",
+		},
+		{
+			name:          "InvalidDuration",
+			loggers:       []*mockLogger{{}, {}},
+			level:         LevelDebug,
+			duration:      -time.Second,
+			expectedError: "invalid duration -1s: must be positive",
+		},
+		{
+			name:             "LoggerError",
+			loggers:          []*mockLogger{{SetLevelForOutError: errors.New("level error")}, {}},
+			level:            LevelTrace,
+			duration:         time.Second,
+			expectedLevel:    LevelTrace,
+			expectedDuration: time.Second,
+			expectedError:    "level error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := &tee{}
+			for _, m := range tc.loggers {
+				logger.loggers = append(logger.loggers, m)
+			}
+
+			cancel, err := logger.SetLevelFor(tc.level, tc.duration)
+
+			assert.NotNil(t, cancel)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			for _, m := range tc.loggers {
+				assert.Equal(t, tc.expectedLevel, m.SetLevelForInLevel)
+				assert.Equal(t, tc.expectedDuration, m.SetLevelForInDuration)
+			}
+		})
+	}
+}
+
+func TestTeeEnabled(t *testing.T) {
+	tests := []struct {
+		name            string
+		loggers         []Logger
+		expectedEnabled bool
+	}{
+		{
+			name:            "NoLogger",
+			loggers:         []Logger{},
+			expectedEnabled: false,
+		},
+		{
+			name:            "Disabled",
+			loggers:         []Logger{&mockLogger{}, &mockLogger{}},
+			expectedEnabled: false,
+		},
+		{
+			name:            "Enabled",
+			loggers:         []Logger{&mockLogger{}, &mockLogger{EnabledOutResult: true}},
+			expectedEnabled: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := &tee{loggers: tc.loggers}
+
+			assert.Equal(t, tc.expectedEnabled, logger.Enabled(LevelDebug))
+		})
+	}
+}
+
+func TestTeeLog(t *testing.T) {
+	m1, m2 := &mockLogger{}, &mockLogger{}
+	logger := &tee{loggers: []Logger{m1, m2}}
+
+	message := "operation succeeded"
+	kv := []interface{}{"operation", "test"}
+	format := "operation succeeded: %s"
+	args := []interface{}{"test"}
+
+	logger.Log(LevelWarn, message, kv...)
+	logger.Logf(LevelWarn, format, args...)
+	logger.Trace(message, kv...)
+	logger.Tracef(format, args...)
+	logger.Debug(message, kv...)
+	logger.Debugf(format, args...)
+	logger.Info(message, kv...)
+	logger.Infof(format, args...)
+	logger.Warn(message, kv...)
+	logger.Warnf(format, args...)
+	logger.Error(message, kv...)
+	logger.Errorf(format, args...)
+
+	for _, m := range []*mockLogger{m1, m2} {
+		assert.Equal(t, LevelWarn, m.LogInLevel)
+		assert.Equal(t, message, m.LogInMessage)
+		assert.Equal(t, kv, m.LogInKV)
+		assert.Equal(t, LevelWarn, m.LogfInLevel)
+		assert.Equal(t, format, m.LogfInFormat)
+		assert.Equal(t, args, m.LogfInArgs)
+		assert.Equal(t, message, m.TraceInMessage)
+		assert.Equal(t, kv, m.TraceInKV)
+		assert.Equal(t, format, m.TracefInFormat)
+		assert.Equal(t, args, m.TracefInArgs)
+		assert.Equal(t, message, m.DebugInMessage)
+		assert.Equal(t, kv, m.DebugInKV)
+		assert.Equal(t, format, m.DebugfInFormat)
+		assert.Equal(t, args, m.DebugfInArgs)
+		assert.Equal(t, message, m.InfoInMessage)
+		assert.Equal(t, kv, m.InfoInKV)
+		assert.Equal(t, format, m.InfofInFormat)
+		assert.Equal(t, args, m.InfofInArgs)
+		assert.Equal(t, message, m.WarnInMessage)
+		assert.Equal(t, kv, m.WarnInKV)
+		assert.Equal(t, format, m.WarnfInFormat)
+		assert.Equal(t, args, m.WarnfInArgs)
+		assert.Equal(t, message, m.ErrorInMessage)
+		assert.Equal(t, kv, m.ErrorInKV)
+		assert.Equal(t, format, m.ErrorfInFormat)
+		assert.Equal(t, args, m.ErrorfInArgs)
+	}
+}
+
+func TestTeeFatalPanic(t *testing.T) {
+	var codes []int
+	exit := func(code int) {
+		codes = append(codes, code)
+	}
+
+	kitBuf, zapBuf := new(bytes.Buffer), new(bytes.Buffer)
+	m := &mockLogger{}
+	logger := NewTee(
+		NewKit(Options{Writers: []io.Writer{kitBuf}, ExitFunc: exit}),
+		NewZap(Options{Writers: []io.Writer{zapBuf}, ExitFunc: exit}),
+		m,
+	)
+
+	t.Run("Fatal", func(t *testing.T) {
+		codes = nil
+		logger.Fatal("fatal message", "key", "value")
+		logger.Fatalf("fatal message %d", 2)
+
+		// The process is exited once per fatal log.
+		assert.Equal(t, []int{1, 1}, codes)
+		assert.Equal(t, "fatal message", m.FatalInMessage)
+		assert.Equal(t, "fatal message %d", m.FatalfInFormat)
+
+		for _, buf := range []*bytes.Buffer{kitBuf, zapBuf} {
+			assert.Contains(t, buf.String(), `"level":"fatal"`)
+			assert.Contains(t, buf.String(), "fatal message 2")
+			assert.Contains(t, buf.String(), "tee_test.go:")
+		}
+	})
+
+	t.Run("FatalNopLoggers", func(t *testing.T) {
+		// The process is not exited if none of the loggers exits the process.
+		nop := NewTee(NewNopLogger(), NewNopLogger())
+		nop.Fatal("fatal message")
+		nop.Fatalf("fatal message %d", 2)
+		NewTee(NewTailLogger(NewNopLogger(), TailOptions{})).Fatal("fatal message")
+		assert.Nil(t, nop.(exiter).exitFunc())
+	})
+
+	t.Run("FatalOtherLoggers", func(t *testing.T) {
+		// The process is exited using os.Exit if any of the other loggers may exit the process.
+		other := NewTee(NewNopLogger(), &mockLogger{})
+		exit := other.(exiter).exitFunc()
+		assert.Equal(t, reflect.ValueOf(os.Exit).Pointer(), reflect.ValueOf(exit).Pointer())
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		assert.PanicsWithValue(t, "panic message", func() { logger.Panic("panic message", "key", "value") })
+		assert.PanicsWithValue(t, "panic message 2", func() { logger.Panicf("panic message %d", 2) })
+
+		assert.Equal(t, "panic message", m.PanicInMessage)
+		assert.Equal(t, "panic message %d", m.PanicfInFormat)
+
+		for _, buf := range []*bytes.Buffer{kitBuf, zapBuf} {
+			assert.Contains(t, buf.String(), `"level":"panic"`)
+			assert.Contains(t, buf.String(), "panic message 2")
+			assert.NotContains(t, buf.String(), "tee.go:")
+		}
+	})
+}
+
+func TestTeeClose(t *testing.T) {
+	tests := []struct {
+		name          string
+		loggers       []Logger
+		expectedError string
+	}{
+		{
+			name: "NoError",
+			loggers: []Logger{
+				&mockLogger{},
+				&mockLogger{},
+			},
+		},
+		{
+			name: "Errors",
+			loggers: []Logger{
+				&mockLogger{CloseOutError: errors.New("close error 1")},
+				&mockLogger{},
+				&mockLogger{CloseOutError: errors.New("close error 2")},
+			},
+			expectedError: "close error 1; close error 2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := &tee{loggers: tc.loggers}
+
+			err := logger.Close()
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
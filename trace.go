@@ -0,0 +1,163 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Keys of the trace context fields
+const (
+	traceIDKey    = "trace_id"
+	spanIDKey     = "span_id"
+	traceFlagsKey = "trace_flags"
+)
+
+// TraceContext identifies a span in a distributed trace.
+// TraceID, SpanID, and Flags are lowercase hex strings as in a W3C traceparent value.
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Flags   string
+}
+
+// TraceExtractor extracts the trace context of a span from a context.
+// Extract returns false if the context does not carry a trace context.
+//
+// For example, an OpenTelemetry span context can be used as follows:
+//
+//	log.SetTraceExtractor(log.TraceExtractorFunc(func(ctx context.Context) (log.TraceContext, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return log.TraceContext{}, false
+//		}
+//		return log.TraceContext{
+//			TraceID: sc.TraceID().String(),
+//			SpanID:  sc.SpanID().String(),
+//			Flags:   sc.TraceFlags().String(),
+//		}, true
+//	}))
+type TraceExtractor interface {
+	Extract(ctx context.Context) (TraceContext, bool)
+}
+
+// TraceExtractorFunc is an adapter for using a function as a TraceExtractor.
+type TraceExtractorFunc func(ctx context.Context) (TraceContext, bool)
+
+// Extract calls f(ctx).
+func (f TraceExtractorFunc) Extract(ctx context.Context) (TraceContext, bool) {
+	return f(ctx)
+}
+
+// traceExtractorHolder holds the trace extractor, so a nil extractor can be stored in an atomic value.
+type traceExtractorHolder struct {
+	extractor TraceExtractor
+}
+
+var traceExtractor atomic.Value
+
+func init() {
+	traceExtractor.Store(&traceExtractorHolder{
+		extractor: TraceparentExtractor(),
+	})
+}
+
+// SetTraceExtractor sets the extractor used for adding the trace context of a context to log entries.
+// The trace context is added by FromContext and the package-level context functions (InfoContext, etc.)
+// as trace_id, span_id, and trace_flags fields.
+// By default, the trace context is extracted from W3C traceparent values added using ContextWithTraceparent.
+// A nil extractor disables adding the trace context.
+func SetTraceExtractor(e TraceExtractor) {
+	traceExtractor.Store(&traceExtractorHolder{
+		extractor: e,
+	})
+}
+
+// withTraceContext returns a logger that logs the trace context carried by a context.
+// If there is no trace context, the logger itself is returned.
+func withTraceContext(ctx context.Context, l Logger) Logger {
+	e := traceExtractor.Load().(*traceExtractorHolder).extractor
+	if e == nil || ctx == nil {
+		return l
+	}
+
+	tc, ok := e.Extract(ctx)
+	if !ok {
+		return l
+	}
+
+	return l.With(traceIDKey, tc.TraceID, spanIDKey, tc.SpanID, traceFlagsKey, tc.Flags)
+}
+
+type traceparentKey struct{}
+
+// ContextWithTraceparent returns a new context that carries a W3C traceparent value.
+// The value is usually received in the traceparent header of an incoming request.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceparentKey{}, traceparent)
+}
+
+// TraceparentExtractor returns a TraceExtractor for W3C traceparent values added to contexts using ContextWithTraceparent.
+// Invalid traceparent values are ignored.
+func TraceparentExtractor() TraceExtractor {
+	return TraceExtractorFunc(func(ctx context.Context) (TraceContext, bool) {
+		traceparent, ok := ctx.Value(traceparentKey{}).(string)
+		if !ok {
+			return TraceContext{}, false
+		}
+
+		tc, err := ParseTraceparent(traceparent)
+		if err != nil {
+			return TraceContext{}, false
+		}
+
+		return tc, true
+	})
+}
+
+// ParseTraceparent parses a W3C traceparent value (https://www.w3.org/TR/trace-context/#traceparent-header).
+// A traceparent value has the format version-traceid-spanid-flags (00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01).
+func ParseTraceparent(traceparent string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q: expected version-traceid-spanid-flags", traceparent)
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	switch {
+	case !isHex(version, 2) || version == "ff":
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q: invalid version", traceparent)
+	// Version 00 has exactly four parts, but future versions may have more.
+	case version == "00" && len(parts) != 4:
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q: unexpected parts for version 00", traceparent)
+	case !isHex(traceID, 32) || traceID == strings.Repeat("0", 32):
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q: invalid trace id", traceparent)
+	case !isHex(spanID, 16) || spanID == strings.Repeat("0", 16):
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q: invalid span id", traceparent)
+	case !isHex(flags, 2):
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q: invalid flags", traceparent)
+	}
+
+	return TraceContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Flags:   flags,
+	}, nil
+}
+
+// isHex determines whether or not a string is a lowercase hex string of length n.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
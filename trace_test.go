@@ -0,0 +1,167 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID      = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name                 string
+		traceparent          string
+		expectedTraceContext TraceContext
+		expectedError        string
+	}{
+		{
+			name:                 "Valid",
+			traceparent:          testTraceparent,
+			expectedTraceContext: TraceContext{TraceID: testTraceID, SpanID: testSpanID, Flags: "01"},
+		},
+		{
+			name:                 "FutureVersion",
+			traceparent:          "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra",
+			expectedTraceContext: TraceContext{TraceID: testTraceID, SpanID: testSpanID, Flags: "00"},
+		},
+		{
+			name:          "Empty",
+			traceparent:   "",
+			expectedError: `invalid traceparent "": expected version-traceid-spanid-flags`,
+		},
+		{
+			name:          "InvalidVersion",
+			traceparent:   "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expectedError: `invalid traceparent "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": invalid version`,
+		},
+		{
+			name:          "ExtraParts",
+			traceparent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			expectedError: `invalid traceparent "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra": unexpected parts for version 00`,
+		},
+		{
+			name:          "UppercaseTraceID",
+			traceparent:   "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			expectedError: `invalid traceparent "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01": invalid trace id`,
+		},
+		{
+			name:          "ZeroTraceID",
+			traceparent:   "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			expectedError: `invalid traceparent "00-00000000000000000000000000000000-00f067aa0ba902b7-01": invalid trace id`,
+		},
+		{
+			name:          "ZeroSpanID",
+			traceparent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			expectedError: `invalid traceparent "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01": invalid span id`,
+		},
+		{
+			name:          "InvalidFlags",
+			traceparent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+			expectedError: `invalid traceparent "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1": invalid flags`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			traceContext, err := ParseTraceparent(tc.traceparent)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedTraceContext, traceContext)
+			}
+		})
+	}
+}
+
+func TestTraceparentExtractor(t *testing.T) {
+	tests := []struct {
+		name                 string
+		ctx                  context.Context
+		expectedOK           bool
+		expectedTraceContext TraceContext
+	}{
+		{
+			name:       "NoTraceparent",
+			ctx:        context.Background(),
+			expectedOK: false,
+		},
+		{
+			name:       "InvalidTraceparent",
+			ctx:        ContextWithTraceparent(context.Background(), "invalid"),
+			expectedOK: false,
+		},
+		{
+			name:                 "ValidTraceparent",
+			ctx:                  ContextWithTraceparent(context.Background(), testTraceparent),
+			expectedOK:           true,
+			expectedTraceContext: TraceContext{TraceID: testTraceID, SpanID: testSpanID, Flags: "01"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			traceContext, ok := TraceparentExtractor().Extract(tc.ctx)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedTraceContext, traceContext)
+		})
+	}
+}
+
+func TestSetTraceExtractor(t *testing.T) {
+	defer SetTraceExtractor(TraceparentExtractor())
+
+	child := &mockLogger{}
+	logger := &mockLogger{WithOutLogger: child}
+	ctx := NewContext(context.Background(), logger)
+
+	t.Run("Custom", func(t *testing.T) {
+		SetTraceExtractor(TraceExtractorFunc(func(ctx context.Context) (TraceContext, bool) {
+			return TraceContext{TraceID: "trace", SpanID: "span", Flags: "00"}, true
+		}))
+
+		assert.Equal(t, child, FromContext(ctx))
+		assert.Equal(t, []interface{}{"trace_id", "trace", "span_id", "span", "trace_flags", "00"}, logger.WithInKV)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		SetTraceExtractor(nil)
+
+		assert.Equal(t, logger, FromContext(ContextWithTraceparent(ctx, testTraceparent)))
+	})
+}
+
+func TestTrace_Loggers(t *testing.T) {
+	for _, tc := range testBackends {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			logger := tc.newLogger(Options{
+				Writers: []io.Writer{buf},
+			})
+
+			ctx := NewContext(context.Background(), logger)
+			ctx = ContextWithTraceparent(ctx, testTraceparent)
+
+			InfoContext(ctx, "request received")
+			FromContext(ctx).Info("request processed")
+			logger.Info("no trace")
+
+			assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte(`"trace_id":"`+testTraceID+`"`)))
+			assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte(`"span_id":"`+testSpanID+`"`)))
+			assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte(`"trace_flags":"01"`)))
+			assert.Equal(t, 3, bytes.Count(buf.Bytes(), []byte("trace_test.go:")))
+
+			assert.NoError(t, logger.Close())
+		})
+	}
+}
@@ -1,16 +1,18 @@
 package log
 
 import (
-	"strings"
+	"fmt"
+	"sort"
+	"time"
 
 	zaplog "go.uber.org/zap"
 	zapcore "go.uber.org/zap/zapcore"
 )
 
-const (
-	instanceCallerSkip  = 1
-	singletonCallerSkip = 2
-)
+const instanceCallerSkip = 1
+
+// zapTraceLevel is a custom zap level below the debug level for trace logs.
+const zapTraceLevel = zapcore.DebugLevel - 1
 
 // zapLogger is an interface for zap.Logger struct.
 type zapLogger interface {
@@ -34,13 +36,331 @@ type zapSugaredLogger interface {
 
 // zap is an implementation of Logger using zap.
 type zap struct {
+	name          string
 	config        *zaplog.Config
+	levels        *levelRegistry
+	recorder      *flightRecorder
+	outputs       *outputs
+	owner         bool
+	exit          func(int)
 	logger        zapLogger
 	sugaredLogger zapSugaredLogger
 }
 
+// zapEntryLevel returns the level of a zap log entry.
+func zapEntryLevel(l zapcore.Level) Level {
+	switch l {
+	case zapTraceLevel:
+		return LevelTrace
+	case zapcore.DebugLevel:
+		return LevelDebug
+	case zapcore.InfoLevel:
+		return LevelInfo
+	case zapcore.WarnLevel:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+// zapLevelName returns the lowercase name of a zap level including the custom trace level.
+func zapLevelName(l zapcore.Level) string {
+	if l == zapTraceLevel {
+		return kitTraceValue
+	}
+	return l.String()
+}
+
+// zapLevelEncoder encodes the custom trace level in addition to the zap levels.
+func zapLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(zapLevelName(l))
+}
+
+// zapFields converts a list of key-value pairs to zap fields.
+// Keys that are not strings are formatted as strings.
+// Like the sugared logger, a key without a value is ignored and reported as an error using the given logger.
+func zapFields(logger *zaplog.Logger, kv []interface{}) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, len(kv)/2)
+	for i := 0; i < len(kv)-1; i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields = append(fields, zaplog.Any(key, kv[i+1]))
+	}
+
+	if len(kv)%2 == 1 {
+		// Skip the stack frame of this function for reporting the caller.
+		logger.WithOptions(zaplog.AddCallerSkip(1)).Error("Ignored key without a value.", zaplog.Any("ignored", kv[len(kv)-1]))
+	}
+
+	return fields
+}
+
+// zapLevel returns the zap level for a logging level.
+// LevelNone is mapped to a level higher than all zap levels, so nothing is logged.
+func zapLevel(l Level) zapcore.Level {
+	switch l {
+	case LevelTrace:
+		return zapTraceLevel
+	case LevelDebug:
+		return zapcore.DebugLevel
+	case LevelInfo:
+		return zapcore.InfoLevel
+	case LevelWarn:
+		return zapcore.WarnLevel
+	case LevelError:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.Level(99)
+	}
+}
+
+// zapLevelStore is a zap atomic level that can be shared between loggers with the same name (see levelRegistry).
+type zapLevelStore struct {
+	zaplog.AtomicLevel
+}
+
+// Load returns the current logging level.
+func (s zapLevelStore) Load() Level {
+	switch s.Level() {
+	case zapTraceLevel:
+		return LevelTrace
+	case zapcore.DebugLevel:
+		return LevelDebug
+	case zapcore.InfoLevel:
+		return LevelInfo
+	case zapcore.WarnLevel:
+		return LevelWarn
+	case zapcore.ErrorLevel:
+		return LevelError
+	default:
+		return LevelNone
+	}
+}
+
+// Store changes the logging level.
+func (s zapLevelStore) Store(l Level) {
+	s.SetLevel(zapLevel(l))
+}
+
+// levelCore is a zap core that filters log entries using a logging level shared between loggers.
+// The level can be replaced for a logger and its children using WrapCore.
+// Entries in the forced level and less verbose levels are logged regardless of the logging level.
+// If record is set, all entries are passed to it regardless of the logging level.
+type levelCore struct {
+	zapcore.Core
+	level  zaplog.AtomicLevel
+	force  Level
+	record zapcore.Core
+}
+
+// enabled determines whether or not the entries in a given level are logged.
+func (c *levelCore) enabled(l zapcore.Level) bool {
+	return c.level.Enabled(l) || zapLevel(c.force).Enabled(l)
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return c.record != nil || c.enabled(l)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	var record zapcore.Core
+	if c.record != nil {
+		record = c.record.With(fields)
+	}
+
+	return &levelCore{
+		Core:   c.Core.With(fields),
+		level:  c.level,
+		force:  c.force,
+		record: record,
+	}
+}
+
+func (c *levelCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.record != nil {
+		ce = c.record.Check(e, ce)
+	}
+
+	if !c.enabled(e.Level) {
+		return ce
+	}
+	return c.Core.Check(e, ce)
+}
+
+// sampledCore is a zap core that samples log entries using a zap sampler.
+// The zap sampler does not support levels below the debug level, so trace entries are not sampled.
+type sampledCore struct {
+	zapcore.Core
+	sampler zapcore.Core
+}
+
+func (c *sampledCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sampledCore{
+		Core:    c.Core.With(fields),
+		sampler: c.sampler.With(fields),
+	}
+}
+
+func (c *sampledCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if e.Level < zapcore.DebugLevel {
+		return c.Core.Check(e, ce)
+	}
+	return c.sampler.Check(e, ce)
+}
+
+// asyncCore is a zap core that queues log entries for being written asynchronously by another core.
+type asyncCore struct {
+	zapcore.Core
+	queue  *asyncQueue
+	errOut zapcore.WriteSyncer
+}
+
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	return &asyncCore{
+		Core:   c.Core.With(fields),
+		queue:  c.queue,
+		errOut: c.errOut,
+	}
+}
+
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.queue.push(zapEntryLevel(ent.Level), func() {
+		if err := c.Core.Write(ent, fields); err != nil {
+			fmt.Fprintf(c.errOut, "%v write error: %v\n", ent.Time, err)
+		}
+	})
+
+	// Entries that may terminate the program are written immediately.
+	if ent.Level > zapcore.ErrorLevel {
+		return c.Sync()
+	}
+
+	return nil
+}
+
+func (c *asyncCore) Sync() error {
+	c.queue.sync()
+	return c.Core.Sync()
+}
+
+// buildZap creates a new zap logger from a zap config.
+// Unlike zap.Config.Build(), the output paths of the config are ignored and logs are written to the given outputs.
+// If the flight recorder is set, all entries are recorded in it regardless of the logging level.
+func buildZap(config *zaplog.Config, outs *outputs, recorder *flightRecorder, callerSkip int) *zaplog.Logger {
+	newEncoder := func() zapcore.Encoder {
+		if config.Encoding == "console" {
+			return zapcore.NewConsoleEncoder(config.EncoderConfig)
+		}
+		return zapcore.NewJSONEncoder(config.EncoderConfig)
+	}
+
+	newCore := func(out zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+		core := zapcore.NewCore(newEncoder(), out, enab)
+		if outs.async != nil {
+			core = &asyncCore{
+				Core:   core,
+				queue:  outs.async,
+				errOut: outs.errOut,
+			}
+		}
+		return core
+	}
+
+	// The logging level is checked by the level core, so the other cores accept all levels.
+	core := newCore(outs.main, zapTraceLevel)
+
+	if len(outs.routes) > 0 {
+		cores := []zapcore.Core{
+			newCore(outs.main, zaplog.LevelEnablerFunc(func(l zapcore.Level) bool {
+				return !outs.routed(zapEntryLevel(l))
+			})),
+		}
+
+		for _, r := range outs.routes {
+			r := r
+			cores = append(cores, newCore(r.out, zaplog.LevelEnablerFunc(func(l zapcore.Level) bool {
+				return r.matches(zapEntryLevel(l))
+			})))
+		}
+
+		core = zapcore.NewTee(cores...)
+	}
+
+	if s := config.Sampling; s != nil {
+		core = &sampledCore{
+			Core:    core,
+			sampler: zapcore.NewSamplerWithOptions(core, time.Second, s.Initial, s.Thereafter),
+		}
+	}
+
+	lc := &levelCore{
+		Core:  core,
+		level: config.Level,
+	}
+
+	if recorder != nil {
+		lc.record = &recordCore{
+			recorder: recorder,
+		}
+	}
+
+	core = lc
+
+	keys := make([]string, 0, len(config.InitialFields))
+	for k := range config.InitialFields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]zaplog.Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, zaplog.Any(k, config.InitialFields[k]))
+	}
+
+	return zaplog.New(core,
+		zaplog.ErrorOutput(outs.errOut),
+		zaplog.AddCaller(),
+		zaplog.AddCallerSkip(callerSkip),
+		zaplog.AddStacktrace(zapcore.ErrorLevel),
+		zaplog.Fields(fields...),
+	)
+}
+
 // NewZap creates a new logger based on zap logger.
+// Invalid levels are treated as "none".
+// It panics if any of the outputs cannot be opened.
 func NewZap(opts Options) Logger {
+	logger, err := newZap(opts)
+	if err != nil {
+		panic(err)
+	}
+	return logger
+}
+
+// NewZapE creates a new logger based on zap logger.
+// Unlike NewZap, it returns an error if the options are invalid or any of the outputs cannot be opened.
+func NewZapE(opts Options) (Logger, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return newZap(opts)
+}
+
+func newZap(opts Options) (Logger, error) {
+	outs, err := openOutputs(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	config := zaplog.NewProductionConfig()
 	config.EncoderConfig.MessageKey = "message"
 	config.EncoderConfig.LevelKey = "level"
@@ -48,13 +368,9 @@ func NewZap(opts Options) Logger {
 	config.EncoderConfig.NameKey = "logger"
 	config.EncoderConfig.CallerKey = "caller"
 	config.EncoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
-	config.OutputPaths = []string{"stdout"}
+	config.EncoderConfig.EncodeLevel = zapLevelEncoder
 	config.InitialFields = make(map[string]interface{})
 
-	if opts.Name != "" {
-		config.InitialFields["logger"] = opts.Name
-	}
-
 	if opts.Version != "" {
 		config.InitialFields["version"] = opts.Version
 	}
@@ -71,22 +387,13 @@ func NewZap(opts Options) Logger {
 		config.InitialFields[k] = v
 	}
 
-	switch strings.ToLower(opts.Level) {
-	case "debug":
-		config.Level = zaplog.NewAtomicLevelAt(zapcore.DebugLevel)
-	case "": // default
-		fallthrough
-	case "info":
-		config.Level = zaplog.NewAtomicLevelAt(zapcore.InfoLevel)
-	case "warn":
-		config.Level = zaplog.NewAtomicLevelAt(zapcore.WarnLevel)
-	case "error":
-		config.Level = zaplog.NewAtomicLevelAt(zapcore.ErrorLevel)
-	case "none":
-		fallthrough
-	default:
-		config.Level = zaplog.NewAtomicLevelAt(zapcore.Level(99))
-	}
+	config.Level = zaplog.NewAtomicLevelAt(zapLevel(parseLevel(opts.Level)))
+
+	// Invalid entries of the level spec are ignored like an invalid level.
+	spec, _ := parseLevelSpec(optionsLevelSpec(opts))
+	levels := newLevelRegistry(opts.Name, zapLevelStore{config.Level}, spec, func(l Level) levelStore {
+		return zapLevelStore{zaplog.NewAtomicLevelAt(zapLevel(l))}
+	})
 
 	switch opts.Format {
 	case FormatJSON:
@@ -95,57 +402,365 @@ func NewZap(opts Options) Logger {
 		config.Encoding = "console"
 	}
 
-	logger, _ := config.Build(
-		zaplog.AddCaller(),
-		zaplog.AddCallerSkip(instanceCallerSkip),
-	)
+	recorder := optionsFlightRecorder(opts)
+	logger := buildZap(&config, outs, recorder, instanceCallerSkip)
+	if opts.Name != "" {
+		logger = logger.Named(opts.Name)
+	}
 
 	return &zap{
+		name:          opts.Name,
 		config:        &config,
+		levels:        levels,
+		recorder:      recorder,
+		outputs:       outs,
+		owner:         true,
+		exit:          opts.ExitFunc,
 		logger:        logger,
 		sugaredLogger: logger.Sugar(),
-	}
+	}, nil
 }
 
 // With returns a new logger that automatically logs the given set of key-value pairs.
 // This can be used for creating a contextualized logger.
+// The new logger shares the logging level with its parent.
 func (z *zap) With(kv ...interface{}) Logger {
 	sugaredLogger := z.sugaredLogger.With(kv...)
 
 	return &zap{
+		name:          z.name,
 		config:        z.config,
+		levels:        z.levels,
+		recorder:      z.recorder,
+		outputs:       z.outputs,
+		exit:          z.exit,
 		logger:        sugaredLogger.Desugar(),
 		sugaredLogger: sugaredLogger,
 	}
 }
 
+// Named returns a new logger whose name is the name of the logger followed by a dot and the given name.
+// The new logger shares the logging level with the loggers having the same name.
+// Its level is the level of its parent unless it is set by a level spec or by changing the level of the new logger.
+// If the logger is created using WithLevel, the new logger shares the logging level with its parent.
+func (z *zap) Named(name string) Logger {
+	if name == "" {
+		return z
+	}
+
+	fullName := joinName(z.name, name)
+	config, logger := z.config, z.sugaredLogger.Desugar().Named(name)
+
+	if z.levels != nil {
+		level := z.levels.level(fullName).(zapLevelStore)
+		config, logger = withAtomicLevel(z.config, logger, level.AtomicLevel)
+	}
+
+	return &zap{
+		name:          fullName,
+		config:        config,
+		levels:        z.levels,
+		recorder:      z.recorder,
+		outputs:       z.outputs,
+		exit:          z.exit,
+		logger:        logger,
+		sugaredLogger: logger.Sugar(),
+	}
+}
+
+// WithLevel returns a new logger that has its own logging level independent of its parent.
+// The loggers created from the new logger using With share the new logging level.
+// Invalid levels are treated as "none".
+func (z *zap) WithLevel(level Level) Logger {
+	if level.validate() != nil {
+		level = LevelNone
+	}
+
+	config, logger := withAtomicLevel(z.config, z.sugaredLogger.Desugar(), zaplog.NewAtomicLevelAt(zapLevel(level)))
+
+	return &zap{
+		name:          z.name,
+		config:        config,
+		recorder:      z.recorder,
+		outputs:       z.outputs,
+		exit:          z.exit,
+		logger:        logger,
+		sugaredLogger: logger.Sugar(),
+	}
+}
+
+// withAtomicLevel returns a copy of a zap config and a zap logger that use a given atomic level.
+func withAtomicLevel(config *zaplog.Config, logger *zaplog.Logger, level zaplog.AtomicLevel) (*zaplog.Config, *zaplog.Logger) {
+	c := *config
+	c.Level = level
+
+	logger = logger.WithOptions(zaplog.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if lc, ok := core.(*levelCore); ok {
+			return &levelCore{
+				Core:   lc.Core,
+				level:  level,
+				force:  lc.force,
+				record: lc.record,
+			}
+		}
+		return core
+	}))
+
+	return &c, logger
+}
+
+// forceLevel returns a copy of the logger that logs the entries in a given level and less verbose levels regardless of its logging level.
+func (z *zap) forceLevel(level Level) Logger {
+	logger := z.sugaredLogger.Desugar().WithOptions(zaplog.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if lc, ok := core.(*levelCore); ok && level > lc.force {
+			return &levelCore{
+				Core:   lc.Core,
+				level:  lc.level,
+				force:  level,
+				record: lc.record,
+			}
+		}
+		return core
+	}))
+
+	return &zap{
+		name:          z.name,
+		config:        z.config,
+		levels:        z.levels,
+		recorder:      z.recorder,
+		outputs:       z.outputs,
+		exit:          z.exit,
+		logger:        logger,
+		sugaredLogger: logger.Sugar(),
+	}
+}
+
+// logEntry logs an entry recorded earlier with its own timestamp and caller regardless of the logging level.
+// The entry is not recorded in the flight recorder, since it is recorded when it is buffered.
+func (z *zap) logEntry(e entry) {
+	logger := z.sugaredLogger.Desugar()
+
+	// The level core is skipped, so the entry is logged regardless of the logging level.
+	core := logger.Core()
+	if lc, ok := core.(*levelCore); ok {
+		core = lc.Core
+	}
+
+	z.writeEntry(core, e, logger)
+}
+
+// recordEntry records an entry buffered for being logged later in the flight recorder.
+// A key without a value is reported when the entry is logged, so it is not reported here.
+func (z *zap) recordEntry(e entry) {
+	if lc, ok := z.sugaredLogger.Desugar().Core().(*levelCore); ok && lc.record != nil {
+		z.writeEntry(lc.record, e, zaplog.NewNop())
+	}
+}
+
+// writeEntry writes an entry recorded earlier with its own timestamp and caller to a zap core.
+// The given logger reports a key without a value.
+func (z *zap) writeEntry(core zapcore.Core, e entry, logger *zaplog.Logger) {
+	if e.level <= LevelNone || e.level > LevelTrace {
+		return
+	}
+
+	ent := zapcore.Entry{
+		LoggerName: z.name,
+		Time:       e.time,
+		Level:      zapLevel(e.level),
+		Message:    e.message,
+		Caller:     zapcore.NewEntryCaller(e.pc, e.file, e.line, e.pc != 0),
+	}
+
+	if ce := core.Check(ent, nil); ce != nil {
+		ce.Write(zapFields(logger, e.kv)...)
+	}
+}
+
+// addCallerSkip returns a copy of the logger that skips extra stack frames for reporting the caller.
+func (z *zap) addCallerSkip(skip int) Logger {
+	logger := z.sugaredLogger.Desugar().WithOptions(zaplog.AddCallerSkip(skip))
+
+	return &zap{
+		name:          z.name,
+		config:        z.config,
+		levels:        z.levels,
+		recorder:      z.recorder,
+		outputs:       z.outputs,
+		owner:         z.owner,
+		exit:          z.exit,
+		logger:        logger,
+		sugaredLogger: logger.Sugar(),
+	}
+}
+
+// exitFunc returns the function called for exiting the process after a fatal log.
+func (z *zap) exitFunc() func(int) {
+	return exitFunc(z.exit)
+}
+
+// withExitFunc returns a copy of the logger that calls the given function for exiting the process after a fatal log.
+func (z *zap) withExitFunc(exit func(int)) Logger {
+	c := *z
+	c.exit = exit
+	return &c
+}
+
+// asyncStats returns the counters of the logger if it logs asynchronously.
+func (z *zap) asyncStats() AsyncStats {
+	if z.outputs != nil && z.outputs.async != nil {
+		return z.outputs.async.stats()
+	}
+	return AsyncStats{}
+}
+
 // GetLevel returns the current logging level.
 func (z *zap) GetLevel() Level {
-	switch z.config.Level.Level() {
-	case zapcore.DebugLevel:
-		return LevelDebug
-	case zapcore.InfoLevel:
-		return LevelInfo
-	case zapcore.WarnLevel:
-		return LevelWarn
-	case zapcore.ErrorLevel:
-		return LevelError
-	default:
-		return LevelNone
-	}
+	return zapLevelStore{z.config.Level}.Load()
 }
 
-// SetLevel changes the logging level.
+// SetLevel changes the logging level of the logger and all loggers sharing the same level.
+// The named loggers created from the logger that do not have their own levels are changed too.
+// Invalid level names are ignored.
 func (z *zap) SetLevel(level string) {
-	switch strings.ToLower(level) {
-	case "debug":
-		z.config.Level.SetLevel(zapcore.DebugLevel)
-	case "info":
-		z.config.Level.SetLevel(zapcore.InfoLevel)
-	case "warn":
-		z.config.Level.SetLevel(zapcore.WarnLevel)
-	case "error":
-		z.config.Level.SetLevel(zapcore.ErrorLevel)
+	if l, err := ParseLevel(level); err == nil {
+		z.storeLevel(l)
+	}
+}
+
+// SetLevelTo changes the logging level of the logger and all loggers sharing the same level.
+// The named loggers created from the logger that do not have their own levels are changed too.
+// It returns an error if the level is invalid.
+func (z *zap) SetLevelTo(level Level) error {
+	if err := level.validate(); err != nil {
+		return err
+	}
+
+	z.storeLevel(level)
+	return nil
+}
+
+// SetLevelFor changes the logging level of the logger and all loggers sharing the same level for a duration.
+// It returns a function for changing the level back before the duration ends.
+func (z *zap) SetLevelFor(level Level, d time.Duration) (func(), error) {
+	return setLevelFor(z, z.config.Level, level, d)
+}
+
+// storeLevel changes the logging level of the logger and the named loggers under it that do not have their own levels.
+func (z *zap) storeLevel(level Level) {
+	if z.levels != nil {
+		z.levels.set(z.name, level)
+	} else {
+		z.config.Level.SetLevel(zapLevel(level))
+	}
+}
+
+// saveLevel returns a function that changes the logging level back to the current level.
+// If the named logger does not have its own level, the function removes its level instead.
+func (z *zap) saveLevel() func() {
+	if z.levels != nil && !z.levels.hasRule(z.name) {
+		return func() {
+			z.levels.unset(z.name)
+		}
+	}
+
+	level := z.GetLevel()
+	return func() {
+		z.storeLevel(level)
+	}
+}
+
+// namedLevels returns the levels of the named loggers created from the same root logger.
+func (z *zap) namedLevels() map[string]Level {
+	if z.levels != nil {
+		return z.levels.list()
+	}
+	return map[string]Level{}
+}
+
+// hasNamedLevel determines whether or not a named logger under the logger has been created or has a level in the level spec.
+func (z *zap) hasNamedLevel(name string) bool {
+	return z.levels != nil && z.levels.has(joinName(z.name, name))
+}
+
+// setLevelSpec changes the logging levels of all loggers created from the same root logger using a level spec.
+func (z *zap) setLevelSpec(spec levelSpec) {
+	if z.levels != nil {
+		z.levels.apply(spec)
+	}
+}
+
+// Enabled determines whether or not the logger logs in a given level.
+func (z *zap) Enabled(level Level) bool {
+	if level <= LevelNone || level > LevelTrace {
+		return false
+	}
+
+	// The level core passes all entries if the flight recorder is enabled, so its logging level is checked instead.
+	core := z.sugaredLogger.Desugar().Core()
+	if lc, ok := core.(*levelCore); ok {
+		return lc.enabled(zapLevel(level))
+	}
+	return core.Enabled(zapLevel(level))
+}
+
+// Log logs a message and a list of key-value pairs in a given level.
+func (z *zap) Log(level Level, message string, kv ...interface{}) {
+	switch level {
+	case LevelError:
+		z.sugaredLogger.Errorw(message, kv...)
+	case LevelWarn:
+		z.sugaredLogger.Warnw(message, kv...)
+	case LevelInfo:
+		z.sugaredLogger.Infow(message, kv...)
+	case LevelDebug:
+		z.sugaredLogger.Debugw(message, kv...)
+	case LevelTrace:
+		logger := z.sugaredLogger.Desugar()
+		if ce := logger.Check(zapTraceLevel, message); ce != nil {
+			ce.Write(zapFields(logger, kv)...)
+		}
+	}
+}
+
+// Logf formats and logs a message in a given level.
+// It uses fmt.Sprintf() to log a message.
+func (z *zap) Logf(level Level, format string, args ...interface{}) {
+	switch level {
+	case LevelError:
+		z.sugaredLogger.Errorf(format, args...)
+	case LevelWarn:
+		z.sugaredLogger.Warnf(format, args...)
+	case LevelInfo:
+		z.sugaredLogger.Infof(format, args...)
+	case LevelDebug:
+		z.sugaredLogger.Debugf(format, args...)
+	case LevelTrace:
+		logger := z.sugaredLogger.Desugar()
+		if logger.Core().Enabled(zapTraceLevel) {
+			if ce := logger.Check(zapTraceLevel, fmt.Sprintf(format, args...)); ce != nil {
+				ce.Write()
+			}
+		}
+	}
+}
+
+// Trace logs a message and a list of key-value pairs in trace level.
+func (z *zap) Trace(message string, kv ...interface{}) {
+	logger := z.sugaredLogger.Desugar()
+	if ce := logger.Check(zapTraceLevel, message); ce != nil {
+		ce.Write(zapFields(logger, kv)...)
+	}
+}
+
+// Tracef formats and logs a message in trace level.
+// It uses fmt.Sprintf() to log a message.
+func (z *zap) Tracef(format string, args ...interface{}) {
+	logger := z.sugaredLogger.Desugar()
+	if logger.Core().Enabled(zapTraceLevel) {
+		if ce := logger.Check(zapTraceLevel, fmt.Sprintf(format, args...)); ce != nil {
+			ce.Write()
+		}
 	}
 }
 
@@ -193,7 +808,60 @@ func (z *zap) Errorf(format string, args ...interface{}) {
 	z.sugaredLogger.Errorf(format, args...)
 }
 
+// Fatal logs a message and a list of key-value pairs in fatal level.
+// It then flushes the logger and exits the process.
+func (z *zap) Fatal(message string, kv ...interface{}) {
+	// zap exits the process after writing a fatal entry, so the entry is written without any further action.
+	logger := z.sugaredLogger.Desugar()
+	if ce := logger.Check(zapcore.FatalLevel, message); ce != nil {
+		ce.Should(ce.Entry, zapcore.WriteThenNoop).Write(zapFields(logger, kv)...)
+	}
+	_ = z.sugaredLogger.Sync()
+	dumpOnFatal(z.recorder, z.outputs)
+	z.exitFunc()(1)
+}
+
+// Fatalf formats and logs a message in fatal level.
+// It uses fmt.Sprintf() to log a message.
+// It then flushes the logger and exits the process.
+func (z *zap) Fatalf(format string, args ...interface{}) {
+	if ce := z.sugaredLogger.Desugar().Check(zapcore.FatalLevel, fmt.Sprintf(format, args...)); ce != nil {
+		ce.Should(ce.Entry, zapcore.WriteThenNoop).Write()
+	}
+	_ = z.sugaredLogger.Sync()
+	dumpOnFatal(z.recorder, z.outputs)
+	z.exitFunc()(1)
+}
+
+// Panic logs a message and a list of key-value pairs in panic level.
+// It then flushes the logger and panics with the message.
+func (z *zap) Panic(message string, kv ...interface{}) {
+	logger := z.sugaredLogger.Desugar()
+	if ce := logger.Check(zapcore.PanicLevel, message); ce != nil {
+		ce.Should(ce.Entry, zapcore.WriteThenNoop).Write(zapFields(logger, kv)...)
+	}
+	_ = z.sugaredLogger.Sync()
+	panic(message)
+}
+
+// Panicf formats and logs a message in panic level.
+// It uses fmt.Sprintf() to log a message.
+// It then flushes the logger and panics with the message.
+func (z *zap) Panicf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if ce := z.sugaredLogger.Desugar().Check(zapcore.PanicLevel, message); ce != nil {
+		ce.Should(ce.Entry, zapcore.WriteThenNoop).Write()
+	}
+	_ = z.sugaredLogger.Sync()
+	panic(message)
+}
+
 // Close flushes the logger.
+// If the logger is not created using With, its outputs are closed too.
 func (z *zap) Close() error {
+	// Closing the outputs flushes them too, and it gives up writing the queued entries after the drain timeout unlike syncing.
+	if z.owner {
+		return z.outputs.Close()
+	}
 	return z.sugaredLogger.Sync()
 }
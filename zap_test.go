@@ -1,7 +1,10 @@
 package log
 
 import (
+	"bytes"
 	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -87,6 +90,53 @@ func (m *mockZapSugaredLogger) Errorf(template string, args ...interface{}) {
 	m.ErrorfInTemplate, m.ErrorfInArgs = template, args
 }
 
+func TestZapLevel(t *testing.T) {
+	tests := []struct {
+		name             string
+		level            Level
+		expectedZapLevel zapcore.Level
+	}{
+		{"None", LevelNone, zapcore.Level(99)},
+		{"Error", LevelError, zapcore.ErrorLevel},
+		{"Warn", LevelWarn, zapcore.WarnLevel},
+		{"Info", LevelInfo, zapcore.InfoLevel},
+		{"Debug", LevelDebug, zapcore.DebugLevel},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedZapLevel, zapLevel(tc.level))
+		})
+	}
+}
+
+func TestLevelCore(t *testing.T) {
+	buf := new(bytes.Buffer)
+	encoder := zapcore.NewJSONEncoder(zaplog.NewProductionEncoderConfig())
+	level := zaplog.NewAtomicLevelAt(zapcore.InfoLevel)
+	core := &levelCore{
+		Core:  zapcore.NewCore(encoder, zapcore.AddSync(buf), zapcore.DebugLevel),
+		level: level,
+	}
+
+	assert.False(t, core.Enabled(zapcore.DebugLevel))
+	assert.True(t, core.Enabled(zapcore.InfoLevel))
+
+	child := core.With([]zapcore.Field{zaplog.String("context", "test")})
+	assert.IsType(t, &levelCore{}, child)
+
+	logger := zaplog.New(child)
+	logger.Debug("debug message")
+	logger.Info("info message")
+	assert.NotContains(t, buf.String(), "debug message")
+	assert.Contains(t, buf.String(), `"context":"test"`)
+
+	// The child shares the level with its parent.
+	level.SetLevel(zapcore.DebugLevel)
+	logger.Debug("debug message")
+	assert.Contains(t, buf.String(), "debug message")
+}
+
 func TestNewZap(t *testing.T) {
 	tests := []struct {
 		name string
@@ -167,6 +217,108 @@ func TestNewZap(t *testing.T) {
 	}
 }
 
+func TestNewZapE(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          Options
+		expectedError string
+	}{
+		{
+			name: "Default",
+			opts: Options{},
+		},
+		{
+			name: "Production",
+			opts: Options{
+				Name:    "my-service",
+				Version: "0.1.0",
+				Level:   "Warn",
+				Format:  FormatConsole,
+			},
+		},
+		{
+			name:          "InvalidLevel",
+			opts:          Options{Level: "warning"},
+			expectedError: `invalid level "warning": must be one of trace, debug, info, warn, error, or none`,
+		},
+		{
+			name:          "InvalidOutputPath",
+			opts:          Options{OutputPaths: []string{"http://localhost"}},
+			expectedError: `invalid output path "http://localhost": unsupported scheme "http"`,
+		},
+		{
+			name:          "OutputPathNotOpened",
+			opts:          Options{OutputPaths: []string{"/missing/directory/app.log"}},
+			expectedError: "open /missing/directory/app.log: no such file or directory",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger, err := NewZapE(tc.opts)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, logger)
+			} else {
+				assert.NoError(t, err)
+				assert.IsType(t, &zap{}, logger)
+				assert.Equal(t, parseLevel(tc.opts.Level), logger.GetLevel())
+				assert.NoError(t, logger.Close())
+			}
+		})
+	}
+}
+
+func TestZapOutputs(t *testing.T) {
+	tests := []struct {
+		name           string
+		writer         *mockWriter
+		message        string
+		expectedOut    string
+		expectedErrOut string
+	}{
+		{
+			name:           "Success",
+			writer:         &mockWriter{WriteOutN: 1024},
+			message:        "operation succeeded",
+			expectedOut:    `"message":"operation succeeded"`,
+			expectedErrOut: "",
+		},
+		{
+			name:           "WriteError",
+			writer:         &mockWriter{WriteOutError: errors.New("disk full")},
+			message:        "operation failed",
+			expectedOut:    `"message":"operation failed"`,
+			expectedErrOut: "write error: disk full",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+			logger := NewZap(Options{
+				Writers:      []io.Writer{out, tc.writer},
+				ErrorWriters: []io.Writer{errOut},
+			})
+
+			logger.Info(tc.message)
+
+			assert.Contains(t, out.String(), tc.expectedOut)
+			assert.Contains(t, string(tc.writer.WriteInP), tc.expectedOut)
+			assert.Contains(t, errOut.String(), tc.expectedErrOut)
+		})
+	}
+
+	t.Run("InvalidOutputPath", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewZap(Options{
+				OutputPaths: []string{"http://localhost"},
+			})
+		})
+	})
+}
+
 func TestZapWith(t *testing.T) {
 	zlogger := zaplog.NewNop()
 
@@ -208,6 +360,31 @@ func TestZapWith(t *testing.T) {
 	}
 }
 
+func TestZapWithLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := NewZap(Options{
+		Level:   "info",
+		Writers: []io.Writer{buf},
+	})
+
+	child := logger.WithLevel(LevelDebug)
+
+	assert.IsType(t, &zap{}, child)
+	assert.Equal(t, LevelDebug, child.GetLevel())
+	assert.Equal(t, LevelInfo, logger.GetLevel())
+
+	child.Debug("child debug")
+	logger.Debug("parent debug")
+	assert.Contains(t, buf.String(), "child debug")
+	assert.NotContains(t, buf.String(), "parent debug")
+
+	child.SetLevel("error")
+	assert.Equal(t, LevelError, child.GetLevel())
+	assert.Equal(t, LevelInfo, logger.GetLevel())
+
+	assert.NoError(t, logger.Close())
+}
+
 func TestZapGetLevel(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -303,6 +480,30 @@ func TestZapSetLevel(t *testing.T) {
 			level:         "error",
 			expectedLevel: zapcore.ErrorLevel,
 		},
+		{
+			name: "None",
+			config: &zaplog.Config{
+				Level: zaplog.NewAtomicLevel(),
+			},
+			level:         "none",
+			expectedLevel: zapLevel(LevelNone),
+		},
+		{
+			name: "Trace",
+			config: &zaplog.Config{
+				Level: zaplog.NewAtomicLevel(),
+			},
+			level:         "trace",
+			expectedLevel: zapTraceLevel,
+		},
+		{
+			name: "Invalid",
+			config: &zaplog.Config{
+				Level: zaplog.NewAtomicLevel(),
+			},
+			level:         "invalid",
+			expectedLevel: zapcore.InfoLevel,
+		},
 	}
 
 	for _, tc := range tests {
@@ -439,6 +640,43 @@ func TestZapLogf(t *testing.T) {
 	}
 }
 
+func TestZapKeyWithoutValue(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var code int
+	logger := NewZap(Options{Level: "trace", Writers: []io.Writer{buf}, ExitFunc: func(c int) { code = c }})
+
+	// A key without a value is reported the same way the sugared logger reports it.
+	tests := []struct {
+		name string
+		log  func()
+	}{
+		{"Debug", func() { logger.Debug("debug message", "key", "value", "dangling") }},
+		{"Trace", func() { logger.Trace("trace message", "key", "value", "dangling") }},
+		{"LogTrace", func() { logger.Log(LevelTrace, "trace message", "key", "value", "dangling") }},
+		{"Fatal", func() { logger.Fatal("fatal message", "key", "value", "dangling") }},
+		{"Panic", func() { assert.Panics(t, func() { logger.Panic("panic message", "key", "value", "dangling") }) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			tc.log()
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			assert.Len(t, lines, 2)
+			assert.Contains(t, lines[0], `"level":"error"`)
+			assert.Contains(t, lines[0], `"message":"Ignored key without a value."`)
+			assert.Contains(t, lines[0], `"ignored":"dangling"`)
+			assert.Contains(t, lines[0], "zap_test.go:")
+			assert.Contains(t, lines[1], `"key":"value"`)
+			assert.NotContains(t, lines[1], "dangling")
+		})
+	}
+
+	assert.Equal(t, 1, code)
+	assert.NoError(t, logger.Close())
+}
+
 func TestZapClose(t *testing.T) {
 	tests := []struct {
 		name                 string